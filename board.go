@@ -0,0 +1,187 @@
+package durak
+
+// Player identifies a seat at the table. Seats are numbered clockwise from 0,
+// the human sits at seat 0 and the computer players after them.
+type Player int
+
+// TableCards is a single slot on the table: an attack card and the card that
+// covers it, if any.
+type TableCards struct {
+	// Attack is the card played by the attacker.
+	Attack Card
+	// Defense is the card that beat Attack. It is only meaningful when
+	// Covered is set.
+	Defense Card
+	// Covered reports whether the defender has beaten Attack.
+	Covered bool
+}
+
+// Board is the complete state of a game of Durak. It is the value the Engine
+// and the AI operate on.
+type Board struct {
+	// Hands holds the hand of every seat, in clockwise seating order.
+	Hands [][]Card
+	// Table holds the attack/defense pairs of the current round, in play
+	// order.
+	Table []TableCards
+	// TrumpSuit is the trump suit for the whole game.
+	TrumpSuit Suit
+	// TrumpCard is the card turned face up under the deck. It is the last
+	// card of Deck and therefore the last card drawn; everybody knows it.
+	TrumpCard Card
+	// Attacker is the seat leading the current round.
+	Attacker Player
+	// Leader is the seat that opened the current round. It stays the same
+	// when a transfer makes the defender the attacker, and draws first
+	// when the round ends.
+	Leader Player
+	// Defender is the seat defending in the current round.
+	Defender Player
+	// Passes counts the throwers that passed since the defender last
+	// covered. Throwers act in clockwise order starting with the attacker.
+	Passes int
+	// Deck is the draw pile. Cards are drawn from the front.
+	Deck []Card
+	// Round counts the rounds finished so far.
+	Round int
+}
+
+// Copy returns a copy of the board for easy modification
+func (b *Board) Copy() *Board {
+	newB := &Board{
+		Hands:     make([][]Card, len(b.Hands)),
+		Table:     make([]TableCards, len(b.Table)),
+		TrumpSuit: b.TrumpSuit,
+		TrumpCard: b.TrumpCard,
+		Attacker:  b.Attacker,
+		Leader:    b.Leader,
+		Defender:  b.Defender,
+		Passes:    b.Passes,
+		Deck:      make([]Card, len(b.Deck)),
+		Round:     b.Round,
+	}
+	for i, hand := range b.Hands {
+		newB.Hands[i] = make([]Card, len(hand))
+		copy(newB.Hands[i], hand)
+	}
+	copy(newB.Table, b.Table)
+	copy(newB.Deck, b.Deck)
+	return newB
+}
+
+// TrumpInDeck reports whether the face-up trump card is still waiting to be
+// drawn.
+func (b *Board) TrumpInDeck() bool {
+	return len(b.Deck) > 0
+}
+
+// Players returns the number of seats at the table.
+func (b *Board) Players() int {
+	return len(b.Hands)
+}
+
+// InGame reports whether seat p is still playing. A player who has emptied
+// their hand once the deck is gone is out.
+func (b *Board) InGame(p Player) bool {
+	return len(b.Hands[p]) > 0 || len(b.Deck) > 0
+}
+
+// Next returns the first seat clockwise after p that is still in the game.
+// It returns p if nobody else is.
+func (b *Board) Next(p Player) Player {
+	n := Player(len(b.Hands))
+	for i := Player(1); i < n; i++ {
+		if q := (p + i) % n; b.InGame(q) {
+			return q
+		}
+	}
+	return p
+}
+
+// Throwers returns the seats that may throw in cards this round: every
+// player other than the defender who holds cards, clockwise from the
+// attacker.
+func (b *Board) Throwers() []Player {
+	var throwers []Player
+	n := Player(len(b.Hands))
+	for i := Player(0); i < n; i++ {
+		p := (b.Attacker + i) % n
+		if p != b.Defender && len(b.Hands[p]) > 0 {
+			throwers = append(throwers, p)
+		}
+	}
+	return throwers
+}
+
+// ToMove returns the seat that has to act next. The defender moves while an
+// attack is uncovered, otherwise the throwers may throw in or pass in turn.
+// It returns -1 if nobody can act.
+func (b *Board) ToMove() Player {
+	if b.Uncovered() > 0 {
+		return b.Defender
+	}
+	throwers := b.Throwers()
+	if b.Passes < len(throwers) {
+		return throwers[b.Passes]
+	}
+	return -1
+}
+
+// SetAttacker starts a fresh round led by seat p against the next player.
+func (b *Board) SetAttacker(p Player) {
+	b.Attacker = p
+	b.Leader = p
+	b.Defender = b.Next(p)
+	b.Passes = 0
+}
+
+// Hand returns the hand held by seat p.
+func (b *Board) Hand(p Player) []Card {
+	return b.Hands[p]
+}
+
+// Uncovered returns the number of attack cards the defender has not beaten.
+func (b *Board) Uncovered() int {
+	n := 0
+	for _, tc := range b.Table {
+		if !tc.Covered {
+			n++
+		}
+	}
+	return n
+}
+
+// TableRanks returns the set of ranks showing on the table. Only cards of
+// these ranks may be thrown in.
+func (b *Board) TableRanks() map[Rank]bool {
+	ranks := make(map[Rank]bool, 2*len(b.Table))
+	for _, tc := range b.Table {
+		ranks[tc.Attack.Rank] = true
+		if tc.Covered {
+			ranks[tc.Defense.Rank] = true
+		}
+	}
+	return ranks
+}
+
+// CardsInPlay returns the number of cards in the hands, on the table and in
+// the deck: every card not discarded yet.
+func (b *Board) CardsInPlay() int {
+	cards := len(b.Deck) + len(b.TableCardList())
+	for _, hand := range b.Hands {
+		cards += len(hand)
+	}
+	return cards
+}
+
+// TableCardList returns every card on the table, attack and defense alike.
+func (b *Board) TableCardList() []Card {
+	cards := make([]Card, 0, 2*len(b.Table))
+	for _, tc := range b.Table {
+		cards = append(cards, tc.Attack)
+		if tc.Covered {
+			cards = append(cards, tc.Defense)
+		}
+	}
+	return cards
+}
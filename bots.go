@@ -0,0 +1,161 @@
+package durak
+
+import (
+	"context"
+	"time"
+)
+
+// ruleBot is an AI that plays by a fixed rule instead of searching. It is
+// deterministic: the same game always gets the same moves, and ties go to
+// the move GetLegalMoves lists first.
+type ruleBot struct {
+	engine GameEngine
+	choose func(board *Board, moves []Move) Move
+	// knowledge is what the bot has seen of the game, if it saw the game
+	// dealt.
+	knowledge *Knowledge
+}
+
+// NewGame starts keeping track of the cards of a new game.
+func (b *ruleBot) NewGame(board *Board) {
+	b.knowledge = NewKnowledge(board.Players())
+}
+
+// Observe records the cards seen.
+func (b *ruleBot) Observe(before *Board, move Move, after *Board) {
+	if b.knowledge != nil {
+		b.knowledge.Observe(before, move, after)
+	}
+}
+
+// spareTrumps reports whether seat holds more trumps than are still out,
+// so that it can give one up and still be sure to hold the most. A bot that
+// did not see the game dealt cannot tell.
+func (b *ruleBot) spareTrumps(board *Board, seat Player) bool {
+	if b.knowledge == nil {
+		return false
+	}
+	held := 0
+	for _, card := range board.Hand(seat) {
+		if card.Suit == board.TrumpSuit {
+			held++
+		}
+	}
+	return held > b.knowledge.TrumpsOut(board, seat)
+}
+
+// Solve returns the move the bot's rule picks.
+func (b *ruleBot) Solve(ctx context.Context, board *Board) Decision {
+	start := time.Now()
+	moves := b.engine.GetLegalMoves(board)
+	if len(moves) == 0 {
+		return Decision{Move: Move{Kind: MoveTake, Seat: board.ToMove()}}
+	}
+	return Decision{Move: b.choose(board, moves), Elapsed: time.Since(start)}
+}
+
+// NewGreedyBot returns a bot that always plays its lowest card: it attacks,
+// throws in, beats and transfers with the cheapest card it may, trumps
+// counting above plain cards. It only takes or passes when it cannot play a
+// card at all.
+func NewGreedyBot(engine GameEngine) AI {
+	return &ruleBot{engine: engine, choose: func(board *Board, moves []Move) Move {
+		if move, ok := cheapest(board, moves, func(Move) bool { return true }); ok {
+			return move
+		}
+		return noCard(moves)
+	}}
+}
+
+// NewTrumpHoarderBot returns a bot that plays like the greedy bot but does
+// not give up a trump while the deck lasts, unless it counts more trumps in
+// its hand than are still out: it takes rather than beat with one and does
+// not throw them in. It only attacks with a trump if it holds nothing else.
+func NewTrumpHoarderBot(engine GameEngine) AI {
+	b := &ruleBot{engine: engine}
+	b.choose = func(board *Board, moves []Move) Move {
+		spare := b.spareTrumps(board, moves[0].Seat)
+		plain := func(m Move) bool {
+			return len(board.Deck) == 0 || m.Card.Suit != board.TrumpSuit || spare
+		}
+		if move, ok := cheapest(board, moves, plain); ok {
+			return move
+		}
+		if moves[0].Kind == MoveAttack {
+			move, _ := cheapest(board, moves, func(Move) bool { return true })
+			return move
+		}
+		return noCard(moves)
+	}
+	return b
+}
+
+// NewAggressiveBot returns a bot that piles on: it attacks with the rank it
+// holds most of, so that it can throw in the rest, throws in every card it
+// can except trumps it cannot spare while the deck lasts, and transfers
+// whenever it may. It beats with its cheapest card.
+func NewAggressiveBot(engine GameEngine) AI {
+	b := &ruleBot{engine: engine}
+	b.choose = func(board *Board, moves []Move) Move {
+		switch moves[0].Kind {
+		case MoveAttack:
+			count := make(map[Rank]int)
+			for _, card := range board.Hand(moves[0].Seat) {
+				count[card.Rank]++
+			}
+			best := moves[0]
+			for _, move := range moves[1:] {
+				n, m := count[move.Card.Rank], count[best.Card.Rank]
+				if n > m || n == m && cardCost(board, move.Card) < cardCost(board, best.Card) {
+					best = move
+				}
+			}
+			return best
+		case MoveThrowIn:
+			spare := b.spareTrumps(board, moves[0].Seat)
+			keep := func(m Move) bool {
+				return m.Kind == MoveThrowIn && (len(board.Deck) == 0 || m.Card.Suit != board.TrumpSuit || spare)
+			}
+			if move, ok := cheapest(board, moves, keep); ok {
+				return move
+			}
+			return noCard(moves)
+		}
+		transfer := func(m Move) bool { return m.Kind == MoveTransfer }
+		if move, ok := cheapest(board, moves, transfer); ok {
+			return move
+		}
+		if move, ok := cheapest(board, moves, func(Move) bool { return true }); ok {
+			return move
+		}
+		return noCard(moves)
+	}
+	return b
+}
+
+// cheapest returns the move playing the cheapest card among the moves that
+// play a card and pass keep. It reports false if there is none.
+func cheapest(board *Board, moves []Move, keep func(Move) bool) (Move, bool) {
+	var best Move
+	found := false
+	for _, move := range moves {
+		if !move.Kind.HasCard() || !keep(move) {
+			continue
+		}
+		if !found || cardCost(board, move.Card) < cardCost(board, best.Card) {
+			best, found = move, true
+		}
+	}
+	return best, found
+}
+
+// noCard returns the move that plays no card, taking or passing. Every
+// position but an opening attack has one.
+func noCard(moves []Move) Move {
+	for _, move := range moves {
+		if !move.Kind.HasCard() {
+			return move
+		}
+	}
+	return moves[0]
+}
@@ -0,0 +1,62 @@
+package durak
+
+import (
+	"context"
+	"testing"
+)
+
+func TestBots(t *testing.T) {
+	rules := Rules{Deck: Deck36, Players: 2, Transfer: true, AttackLimit: 6}
+	bots := map[string]func(GameEngine) AI{
+		"greedy":     NewGreedyBot,
+		"hoarder":    NewTrumpHoarderBot,
+		"aggressive": NewAggressiveBot,
+	}
+	// Spades are trump and three cards are left in the deck.
+	tests := []struct {
+		name  string
+		bot   string
+		hands []string
+		table []string // moves played before the bot's turn
+		empty bool     // the deck is gone
+		want  string
+	}{
+		{name: "attack with the lowest card", bot: "greedy", hands: []string{"K♥ 6♠ 7♣", "8♦"}, want: "0 attack 7♣"},
+		{name: "beat with the lowest card", bot: "greedy", hands: []string{"9♥", "A♥ 6♠ 10♥"}, table: []string{"0 attack 9♥"}, want: "1 defend 10♥ 0"},
+		{name: "beat with a trump", bot: "greedy", hands: []string{"9♥", "8♥ 7♠ 6♣"}, table: []string{"0 attack 9♥"}, want: "1 defend 7♠ 0"},
+		{name: "throw in the lowest card", bot: "greedy", hands: []string{"9♥ 10♦ 9♣ K♣", "10♥ 6♣"}, table: []string{"0 attack 9♥", "1 defend 10♥ 0"}, want: "0 throw-in 9♣"},
+
+		{name: "take rather than spend a trump", bot: "hoarder", hands: []string{"9♥", "8♥ 7♠ 6♣"}, table: []string{"0 attack 9♥"}, want: "1 take"},
+		{name: "beat with a trump once the deck is gone", bot: "hoarder", hands: []string{"9♥", "8♥ 7♠ 6♣"}, table: []string{"0 attack 9♥"}, empty: true, want: "1 defend 7♠ 0"},
+		{name: "keep trumps from throw-ins", bot: "hoarder", hands: []string{"9♥ 9♠ 10♣", "10♥ 6♣"}, table: []string{"0 attack 9♥", "1 defend 10♥ 0"}, want: "0 throw-in 10♣"},
+		{name: "attack with a trump if nothing else", bot: "hoarder", hands: []string{"9♠ 6♠", "8♦"}, want: "0 attack 6♠"},
+
+		{name: "attack with the most common rank", bot: "aggressive", hands: []string{"6♥ 9♦ 9♣ K♥", "8♦"}, want: "0 attack 9♦"},
+		{name: "transfer when possible", bot: "aggressive", hands: []string{"9♥ 7♣ 8♣", "A♥ 9♣ 6♦"}, table: []string{"0 attack 9♥"}, want: "1 transfer 9♣"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.bot+": "+tt.name, func(t *testing.T) {
+			parse := func(s string) Move {
+				move, err := ParseMove(s)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return move
+			}
+			e := &Engine{Rules: rules}
+			b := testBoard(t, tt.hands...)
+			if tt.empty {
+				b.Deck = nil
+			}
+			for _, s := range tt.table {
+				if err := e.Apply(b, parse(s)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			bot := bots[tt.bot](e)
+			if got := bot.Solve(context.Background(), b).Move; got != parse(tt.want) {
+				t.Errorf("the bot played %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
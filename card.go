@@ -1,8 +1,9 @@
-package main
+package durak
 
 import (
 	"fmt"
 	"math/rand"
+	"strings"
 	"time"
 )
 
@@ -31,6 +32,14 @@ func (s Suit) String() string {
 	}
 }
 
+// Letter returns the ASCII initial of the suit, e.g. "S" for spades.
+func (s Suit) Letter() string {
+	if s < Clubs || s > Spades {
+		return "?"
+	}
+	return "CDHS"[s : s+1]
+}
+
 type Rank int
 
 const (
@@ -93,17 +102,97 @@ func (c Card) String() string {
 	return fmt.Sprintf("%s%s", c.Rank, c.Suit)
 }
 
-// NewDeck returns a unshuffled deck of cards
-func NewDeck() []Card {
-	cards := make([]Card, 0, 52)
+// ParseRank parses a rank as printed by Rank.String.
+func ParseRank(s string) (Rank, error) {
+	for r := Two; r <= Ace; r++ {
+		if strings.EqualFold(s, r.String()) {
+			return r, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown rank %q", s)
+}
+
+// ParseSuit parses a suit symbol or its initial letter, e.g. "♠" or "S".
+func ParseSuit(s string) (Suit, error) {
 	for suit := Clubs; suit <= Spades; suit++ {
-		for rank := Two; rank <= Ace; rank++ {
+		if s == suit.String() || strings.EqualFold(s, suit.Letter()) {
+			return suit, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown suit %q", s)
+}
+
+// ParseCard parses a card as printed by Card.String, e.g. "10♥". The suit may
+// also be given as a letter, e.g. "10H".
+func ParseCard(s string) (Card, error) {
+	for _, suit := range []Suit{Clubs, Diamonds, Hearts, Spades} {
+		for _, name := range []string{suit.String(), suit.Letter(), strings.ToLower(suit.Letter())} {
+			if rank, ok := strings.CutSuffix(s, name); ok && rank != "" {
+				r, err := ParseRank(rank)
+				if err != nil {
+					return Card{}, fmt.Errorf("card %q: %w", s, err)
+				}
+				return Card{Suit: suit, Rank: r}, nil
+			}
+		}
+	}
+	return Card{}, fmt.Errorf("card %q: unknown suit", s)
+}
+
+// DeckSpec describes which cards make up the deck. Every suit runs from
+// MinRank up to the Ace.
+type DeckSpec struct {
+	MinRank Rank
+}
+
+// Deck presets. Classic Durak is played with 36 cards.
+var (
+	Deck24 = DeckSpec{MinRank: Nine}
+	Deck36 = DeckSpec{MinRank: Six}
+	Deck52 = DeckSpec{MinRank: Two}
+)
+
+// DeckForSize returns the preset with the given number of cards.
+func DeckForSize(size int) (DeckSpec, error) {
+	for _, d := range []DeckSpec{Deck24, Deck36, Deck52} {
+		if d.Size() == size {
+			return d, nil
+		}
+	}
+	return DeckSpec{}, fmt.Errorf("no %d card deck, use 24, 36 or 52", size)
+}
+
+// Size returns the number of cards in the deck.
+func (d DeckSpec) Size() int {
+	return 4 * int(Ace-d.MinRank+1)
+}
+
+// Contains reports whether the card belongs to the deck.
+func (d DeckSpec) Contains(c Card) bool {
+	return c.Rank >= d.MinRank && c.Rank <= Ace && c.Suit >= Clubs && c.Suit <= Spades
+}
+
+// String returns a short description of the deck, e.g. "36 cards (6-A)".
+func (d DeckSpec) String() string {
+	return fmt.Sprintf("%d cards (%s-%s)", d.Size(), d.MinRank, Ace)
+}
+
+// Cards returns an unshuffled deck of cards.
+func (d DeckSpec) Cards() []Card {
+	cards := make([]Card, 0, d.Size())
+	for suit := Clubs; suit <= Spades; suit++ {
+		for rank := d.MinRank; rank <= Ace; rank++ {
 			cards = append(cards, Card{Suit: suit, Rank: rank})
 		}
 	}
 	return cards
 }
 
+// NewDeck returns a unshuffled standard 36 card deck.
+func NewDeck() []Card {
+	return Deck36.Cards()
+}
+
 // ShuffleDeck shuffles a deck of cards.
 func ShuffleDeck(cards []Card) {
 	r := rand.New(rand.NewSource(time.Now().UnixNano()))
@@ -0,0 +1,20 @@
+package durak
+
+import "testing"
+
+func TestDeckForSize(t *testing.T) {
+	for _, want := range []DeckSpec{Deck24, Deck36, Deck52} {
+		got, err := DeckForSize(want.Size())
+		if err != nil || got != want {
+			t.Errorf("DeckForSize(%d) = %v, %v, want %v", want.Size(), got, err, want)
+		}
+		if n := len(got.Cards()); n != want.Size() {
+			t.Errorf("the %d card deck deals %d cards", want.Size(), n)
+		}
+	}
+	for _, size := range []int{0, 20, 32, 54} {
+		if d, err := DeckForSize(size); err == nil {
+			t.Errorf("DeckForSize(%d) = %v, want an error", size, d)
+		}
+	}
+}
@@ -0,0 +1,980 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	durak "github.com/TESTMECS/durakgo"
+)
+
+// human is the seat of the person at the keyboard. Every other seat is played
+// by the AI.
+const human durak.Player = 0
+
+// This Struct is the display state.
+type Game struct {
+	board        *durak.Board
+	engine       *durak.Engine
+	cursor       int
+	durak        durak.Player
+	gameover     bool
+	announcement string
+	err          string // why the player's last move was rejected
+	games        int    // games finished this session
+	lost         int    // games the player finished as the durak
+	colors       map[string]lipgloss.Style
+	settings     settings
+	decision     durak.Decision  // the AI's last decision
+	hinter       durak.AI        // searches for the player's best move
+	hint         *durak.Decision // the hint for the player's move, if asked
+	hinting      bool            // a hint is being searched for
+	menu         bool            // the new-game menu is open
+	choice       int             // the AI highlighted in the menu
+	// ctx is cancelled when the player quits, aborting a search in
+	// progress.
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// settings are the options chosen on the command line. They hold for every
+// game of the session.
+type settings struct {
+	rules    durak.Rules
+	opponent string         // a registered AI, a level or a bot
+	think    time.Duration  // overrides the level's search budget if set
+	workers  int            // overrides the level's search goroutines if set
+	watch    bool           // the AI plays the human's seat as well
+	weights  *durak.Weights // cut the levels' playouts short if set
+	explain  bool           // show the report on the AI's last decision
+}
+
+// ai returns the AI the settings ask for, or the Normal level if there is
+// no such AI.
+func (s settings) ai(engine durak.GameEngine) durak.AI {
+	level, err := durak.ParseLevel(s.opponent)
+	if err != nil {
+		ai, err := durak.NewAI(s.opponent, engine)
+		if err != nil {
+			log.Println(err)
+			return durak.NewMCTS(engine, durak.Normal.Config())
+		}
+		return ai
+	}
+	return durak.NewMCTS(engine, s.config(level))
+}
+
+// config returns the search configuration of level with the overrides of
+// the settings.
+func (s settings) config(level durak.Level) durak.MCTSConfig {
+	cfg := level.Config()
+	if s.think > 0 {
+		cfg.Budget = durak.Budget{Time: s.think}
+	}
+	if s.workers > 0 {
+		cfg.Workers = s.workers
+	}
+	if s.weights != nil {
+		cfg.Evaluator = s.weights
+	}
+	return cfg
+}
+
+// hinter returns the AI that gives the player hints: the Hard level, never
+// slipping on purpose. It observes the game to keep track of the cards.
+func (s settings) hinter(engine *durak.Engine) durak.AI {
+	cfg := s.config(durak.Hard)
+	cfg.MistakeRate = 0
+	ai := durak.NewMCTS(engine, cfg)
+	if o, ok := ai.(durak.Observer); ok {
+		engine.Observers = append(engine.Observers, o)
+	}
+	return ai
+}
+
+// opponentIndex returns the position of the opponent in the new-game menu.
+func (s settings) opponentIndex() int {
+	for i, info := range durak.RegisteredAIs() {
+		if info.Name == s.opponent {
+			return i
+		}
+	}
+	return 0
+}
+
+// Initialize Game Shuffles the deck, deals every seat's hand and
+// Creates the Game Struct. The lowest trump leads the first game of a
+// session; afterwards the durak of the previous game attacks first.
+func initialGame(settings settings, previousDurak durak.Player) *Game {
+	log.Println("Initializing game...")
+	engine := durak.NewEngine(settings.rules)
+	engine.SetAI(settings.ai(engine))
+	hinter := settings.hinter(engine)
+	board := engine.NewBoard()
+
+	var announcement string
+	if previousDurak >= 0 {
+		board.SetAttacker(previousDurak)
+		announcement = fmt.Sprintf("%s lost the last game and %s first.", seatName(previousDurak), verb(previousDurak, "attack"))
+	} else if lead := engine.FirstLead(board); lead.Trump {
+		announcement = fmt.Sprintf("%s %s with %s, the lowest trump.", seatName(lead.Seat), verb(lead.Seat, "lead"), lead.Card)
+	} else {
+		announcement = fmt.Sprintf("Nobody holds a trump. %s %s with %s, the lowest card.", seatName(lead.Seat), verb(lead.Seat, "lead"), lead.Card)
+	}
+	log.Println(announcement)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Game{
+		board:        board,
+		cursor:       0,
+		engine:       engine,
+		hinter:       hinter,
+		announcement: announcement,
+		settings:     settings,
+		choice:       settings.opponentIndex(),
+		ctx:          ctx,
+		cancel:       cancel,
+	}
+}
+
+// verb conjugates a verb to follow the seat's name, e.g. "You lead" but
+// "Computer 1 leads".
+func verb(p durak.Player, v string) string {
+	if p == human {
+		return v
+	}
+	return v + "s"
+}
+
+// startOpponent closes the new-game menu and plays against the AI chosen. At
+// the start of the session the game already dealt goes ahead, otherwise the
+// next one is dealt.
+func (g *Game) startOpponent() tea.Cmd {
+	g.menu = false
+	g.settings.opponent = durak.RegisteredAIs()[g.choice].Name
+	if g.gameover {
+		return g.newGame()
+	}
+	g.engine.SetAI(g.settings.ai(g.engine))
+	return g.startTurn()
+}
+
+// be conjugates "to be" to follow the seat's name.
+func be(p durak.Player) string {
+	if p == human {
+		return "are"
+	}
+	return "is"
+}
+
+// newGame deals the next game of the session.
+func (g *Game) newGame() tea.Cmd {
+	g.cancel()
+	next := initialGame(g.settings, g.durak)
+	next.games, next.lost = g.games, g.lost
+	*g = *next
+	return g.startTurn()
+}
+
+// humanToMove reports whether the person at the keyboard has to act next.
+func (g *Game) humanToMove() bool {
+	return !g.settings.watch && g.board.ToMove() == human
+}
+
+// startTurn hands the turn to the AI if it has to act next.
+func (g *Game) startTurn() tea.Cmd {
+	if g.humanToMove() || g.board.ToMove() < 0 {
+		return nil
+	}
+	return func() tea.Msg { return passTurnToAI{} }
+}
+
+// Tea Init
+func (g *Game) Init() tea.Cmd {
+	if g.menu {
+		return tea.SetWindowTitle("Durak")
+	}
+	return tea.Batch(tea.SetWindowTitle("Durak"), g.startTurn())
+}
+
+// Communication Between Engine and Player
+type passTurnToAI struct{}
+
+// aiFinishedTurn carries the board after the AI's move and its decision. It
+// is stale if the game has moved on from the board the AI played from.
+type aiFinishedTurn struct {
+	from, board *durak.Board
+	decision    durak.Decision
+}
+
+type hintFound struct{ decision durak.Decision }
+
+// askHint searches for the player's best move in the background.
+func (g *Game) askHint() tea.Cmd {
+	g.hinting = true
+	board := g.board.Copy()
+	return func() tea.Msg {
+		log.Println("--- Hint requested ---")
+		return hintFound{g.hinter.Solve(g.ctx, board)}
+	}
+}
+
+// hand returns the player's hand.
+func (g *Game) hand() []durak.Card {
+	return g.board.Hand(human)
+}
+
+// Called after passTurnToAI. The AI plays on a copy of the board, which
+// Update takes over with the decision once it is done, so that nothing the
+// view reads changes under it.
+func aiMove(g *Game) tea.Cmd {
+	from := g.board
+	board := from.Copy()
+	return func() tea.Msg {
+		log.Printf("--- AI Turn: seat %d ---", board.ToMove())
+		log.Printf("AI Turn Start: Attacker %d, Defender %d, Hands %v, Table %v", board.Attacker, board.Defender, board.Hands, board.Table)
+		decision := g.engine.AITurn(g.ctx, board)
+		if report, err := json.Marshal(decision); err == nil {
+			log.Printf("AI Move: %v %s", decision.Move, report)
+		} else {
+			log.Printf("AI Move: %v (%v)", decision.Move, err)
+		}
+		return aiFinishedTurn{from: from, board: board, decision: decision}
+	}
+}
+
+// afterPlayerMove hands the turn to the AI if it has to act next.
+func (g *Game) afterPlayerMove() tea.Cmd {
+	g.announcement = ""
+	g.hint = nil
+	if g.cursor >= len(g.hand()) && len(g.hand()) > 0 {
+		g.cursor = len(g.hand()) - 1
+	}
+	if g.checkGameOver() {
+		return nil
+	}
+	return g.startTurn()
+}
+
+// Main Logic Update function
+func (g *Game) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case passTurnToAI:
+		log.Println("Ai turn in progress...")
+		time.Sleep(time.Second * 1)
+		return g, aiMove(g)
+	case aiFinishedTurn:
+		if msg.from != g.board || g.ctx.Err() != nil {
+			return g, nil
+		}
+		g.board, g.decision = msg.board, msg.decision
+		board := g.board
+		log.Printf("Board state after AITurn: Attacker %d, Defender %d, Hands %v, Table %v", board.Attacker, board.Defender, board.Hands, board.Table)
+		if g.checkGameOver() {
+			return g, nil
+		}
+		// If an AI has to act next, e.g. to throw in after the player
+		// covered or to attack after a successful defense, keep going.
+		if !g.humanToMove() {
+			log.Println("AI moves again, starting another turn.")
+			return g, g.startTurn()
+		}
+		log.Println("--- AI Turn End: Passing to player ---")
+		return g, nil
+	case hintFound:
+		g.hinting = false
+		if g.ctx.Err() == nil {
+			g.hint = &msg.decision
+		}
+		return g, nil
+	case tea.KeyMsg:
+		if g.menu {
+			switch msg.String() {
+			case "ctrl+c", "q":
+				g.cancel()
+				return g, tea.Quit
+			case "up", "k":
+				if g.choice > 0 {
+					g.choice--
+				}
+			case "down", "j":
+				if g.choice < len(durak.RegisteredAIs())-1 {
+					g.choice++
+				}
+			case " ", "enter":
+				return g, g.startOpponent()
+			}
+			return g, nil
+		}
+		switch msg.String() {
+		case "ctrl+c", "q":
+			g.cancel()
+			return g, tea.Quit
+		case "right", "l":
+			if g.cursor < len(g.hand())-1 {
+				g.cursor++
+			}
+		case "left", "h":
+			if g.cursor > 0 {
+				g.cursor--
+			}
+		case "n":
+			if g.gameover {
+				g.menu = true
+				return g, nil
+			}
+		case "e":
+			g.settings.explain = !g.settings.explain
+			return g, nil
+		}
+		// Moves wait for a hint being searched for: the hinter follows
+		// the game and must not see it change under its search.
+		if g.gameover || !g.humanToMove() || g.hinting {
+			return g, nil
+		}
+
+		move := durak.Move{Seat: human}
+		switch msg.String() {
+		case "?":
+			return g, g.askHint()
+		case "p": // Player passes the attack
+			move.Kind = durak.MovePass
+		case "t": // Player takes the cards
+			move.Kind = durak.MoveTake
+		case "r": // Player transfers the attack
+			if len(g.hand()) == 0 {
+				return g, nil
+			}
+			move.Kind = durak.MoveTransfer
+			move.Card = g.hand()[g.cursor]
+		case " ", "enter": // Player attacks, throws in or covers
+			if len(g.hand()) == 0 {
+				return g, nil
+			}
+			move.Card = g.hand()[g.cursor]
+			switch {
+			case g.board.Uncovered() > 0:
+				move.Kind = durak.MoveDefend
+				move.Target = g.defendTarget(move.Card)
+				if h := g.hint; h != nil && h.Move.Kind == durak.MoveDefend && h.Move.Card == move.Card {
+					// Beat the attack the hint meant.
+					move.Target = h.Move.Target
+				}
+			case len(g.board.Table) > 0:
+				move.Kind = durak.MoveThrowIn
+			default:
+				move.Kind = durak.MoveAttack
+			}
+		default:
+			return g, nil
+		}
+
+		log.Printf("--- Player Move: %v ---", move)
+		if err := g.engine.Apply(g.board, move); err != nil {
+			log.Println("Rejected:", err)
+			var moveErr *durak.MoveError
+			if errors.As(err, &moveErr) {
+				g.err = moveErr.Reason.Error()
+			} else {
+				g.err = err.Error()
+			}
+			return g, nil
+		}
+		g.err = ""
+		return g, g.afterPlayerMove()
+	}
+	return g, nil
+}
+
+// defendTarget returns the index of the first attack the defender has not
+// beaten that card beats. If card beats none of them it returns the first
+// one not beaten, for the engine to reject with its reason, and -1 if every
+// attack is covered.
+func (g *Game) defendTarget(card durak.Card) int {
+	first := -1
+	for i, tc := range g.board.Table {
+		if tc.Covered {
+			continue
+		}
+		if g.engine.CanBeat(tc.Attack, card, g.board.TrumpSuit) {
+			return i
+		}
+		if first < 0 {
+			first = i
+		}
+	}
+	return first
+}
+
+// checkGameOver records the result if the game has ended.
+func (g *Game) checkGameOver() bool {
+	isover, durak := g.engine.CheckGameOver(g.board)
+	if isover {
+		g.gameover = true
+		g.durak = durak
+		g.games++
+		if durak == human {
+			g.lost++
+		}
+	}
+	return isover
+}
+
+// seatName returns how a seat is called in the UI.
+func seatName(p durak.Player) string {
+	if p == human {
+		return "You"
+	}
+	return fmt.Sprintf("Computer %d", p)
+}
+
+// renderCardBackLipGloss renders a face-down card.
+// count = number of cards to render side-by-side
+func renderCardBackLipGloss(count int) string {
+	if count <= 0 {
+		return ""
+	}
+
+	// Style for back of the card
+	backStyle := lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(lipgloss.Color("240")). // gray border
+		Background(lipgloss.Color("57")).        // blue background
+		Foreground(lipgloss.Color("230")).       // light foreground for pattern
+		Padding(0, 1)
+
+	// The "pattern" inside the back of the card
+	// You can change to ▒░▓ etc. for texture
+	backText := lipgloss.JoinVertical(lipgloss.Center,
+		"▒▒▒",
+		"▒▒▒",
+		"▒▒▒",
+	)
+
+	// Render N backs side-by-side
+	backs := make([]string, count)
+	for i := 0; i < count; i++ {
+		backs[i] = backStyle.Render(backText)
+	}
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, backs...)
+}
+
+// renderCardsLipGloss renders cards side by side, with the card at cursor
+// and the card at hint, the one a hint recommends, picked out. Either index
+// may be -1.
+func renderCardsLipGloss(cards []durak.Card, cursor int, selected bool, hint int) string {
+	if len(cards) == 0 {
+		return ""
+	}
+
+	// Styles
+	borderColor := lipgloss.Color("240")
+	cursorBorder := lipgloss.Color("69")
+	selectedBorder := lipgloss.Color("190")
+
+	cardStyle := lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(borderColor).
+		Padding(0, 1)
+
+	cursorStyle := cardStyle.Copy().
+		BorderForeground(cursorBorder).
+		Bold(true)
+
+	selectedStyle := cardStyle.Copy().
+		BorderForeground(selectedBorder).
+		Bold(true).
+		Background(lipgloss.Color("236"))
+
+	hintStyle := cardStyle.Copy().
+		BorderStyle(lipgloss.DoubleBorder()).
+		BorderForeground(lipgloss.Color("10")). // green, as the hint
+		Bold(true)
+
+	// Build cards
+	cardViews := make([]string, len(cards))
+	for i, card := range cards {
+		rank := card.Rank.String()
+		suit := card.Suit.String()
+
+		cardText := fmt.Sprintf("%-2s\n  %s\n%2s", rank, suit, rank)
+
+		style := cardStyle
+		if i == hint {
+			style = hintStyle
+		}
+		if i == cursor {
+			if selected {
+				style = selectedStyle
+			} else {
+				style = cursorStyle
+			}
+		}
+
+		cardViews[i] = style.Render(cardText)
+	}
+
+	// Join cards horizontally
+	return lipgloss.JoinHorizontal(lipgloss.Top, cardViews...)
+}
+
+// renderTableLipGloss renders each attack card with its covering card, if
+// any, underneath it.
+func renderTableLipGloss(table []durak.TableCards) string {
+	if len(table) == 0 {
+		return ""
+	}
+
+	// Styles
+	attackStyle := lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(lipgloss.Color("240")).
+		Padding(0, 1)
+
+	defenseStyle := attackStyle.Copy().
+		BorderForeground(lipgloss.Color("10")) // green, the attack is beaten
+
+	// Build pairs
+	pairViews := make([]string, len(table))
+	for i, tc := range table {
+		attack := attackStyle.Render(fmt.Sprintf("%-2s\n  %s\n%2s", tc.Attack.Rank, tc.Attack.Suit, tc.Attack.Rank))
+		if !tc.Covered {
+			pairViews[i] = attack
+			continue
+		}
+		defense := defenseStyle.Render(fmt.Sprintf("%-2s\n  %s\n%2s", tc.Defense.Rank, tc.Defense.Suit, tc.Defense.Rank))
+		pairViews[i] = lipgloss.JoinVertical(lipgloss.Left, attack, defense)
+	}
+
+	// Join pairs horizontally
+	return lipgloss.JoinHorizontal(lipgloss.Top, pairViews...)
+}
+
+func (g *Game) View() string {
+	// ===== Styles =====
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("212")) // pinkish
+
+	sectionStyle := lipgloss.NewStyle().
+		MarginBottom(1) // space between sections
+
+	infoStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("244")) // gray
+
+	gameOverStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("9")) // bright red
+
+	statusStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("10")). // green
+		MarginTop(1)
+
+	// ===== New-game menu =====
+	if g.menu {
+		lines := []string{titleStyle.Render("Choose your opponent:")}
+		for i, info := range durak.RegisteredAIs() {
+			line := fmt.Sprintf("  %-11s %s", info.Name, infoStyle.Render(info.Description))
+			if i == g.choice {
+				line = statusStyle.UnsetMarginTop().Render(fmt.Sprintf("> %-11s", info.Name)) + " " + infoStyle.Render(info.Description)
+			}
+			lines = append(lines, line)
+		}
+		lines = append(lines, "", infoStyle.Render("Use up/down to choose, enter to start, 'q' to quit."))
+		return lipgloss.JoinVertical(lipgloss.Left, lines...)
+	}
+
+	// ===== Game over view =====
+	if g.gameover {
+		var endMsg string
+		switch g.durak {
+		case human:
+			endMsg = "You lose! You are the durak."
+		case -1:
+			endMsg = "It's a draw!"
+		default:
+			endMsg = fmt.Sprintf("You win! %s is the durak.", seatName(g.durak))
+		}
+
+		return lipgloss.JoinVertical(lipgloss.Left,
+			gameOverStyle.Render("Game Over!"),
+			endMsg,
+			infoStyle.Render(fmt.Sprintf("You were the durak in %d of %d games.", g.lost, g.games)),
+			infoStyle.Render("Press 'n' for a new game, 'q' to quit."),
+		)
+	}
+
+	board := g.board
+
+	// ===== Sections =====
+	role := func(p durak.Player) string {
+		switch p {
+		case board.Attacker:
+			return " (attacking)"
+		case board.Defender:
+			return " (defending)"
+		}
+		return ""
+	}
+
+	var sections []string
+	for p := durak.Player(1); p < durak.Player(board.Players()); p++ {
+		opponent := lipgloss.JoinVertical(lipgloss.Left,
+			titleStyle.Render(seatName(p)+"'s hand"+role(p)+":"),
+			renderCardBackLipGloss(len(board.Hand(p))),
+		)
+		sections = append(sections, sectionStyle.Render(opponent))
+	}
+
+	table := lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.Render("Table:"),
+		func() string {
+			if len(board.Table) == 0 {
+				return infoStyle.Render("[empty]")
+			}
+			return renderTableLipGloss(board.Table)
+		}(),
+	)
+
+	player1 := lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.Render("Your hand"+role(human)+":"),
+		renderCardsLipGloss(g.hand(), g.cursor, true, g.hintCard()),
+	)
+
+	deck := lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.Render(fmt.Sprintf("Deck: %d", len(board.Deck))),
+		func() string {
+			if !board.TrumpInDeck() {
+				return infoStyle.Render("[empty]")
+			}
+			// The trump card lies face up under the rest of the deck.
+			return lipgloss.JoinHorizontal(lipgloss.Top,
+				renderCardBackLipGloss(min(len(board.Deck)-1, 1)),
+				renderCardsLipGloss([]durak.Card{board.TrumpCard}, -1, false, -1),
+			)
+		}(),
+	)
+
+	limit := fmt.Sprintf("Attack limit: %d", min(g.engine.AttackLimit(board), len(board.Hand(board.Defender))+len(board.Table)-board.Uncovered()))
+	if board.Round == 0 && g.engine.Rules.FirstAttackLimit > 0 {
+		limit += " (first round)"
+	}
+	gameInfo := infoStyle.Render(
+		fmt.Sprintf("Trump: %s | %s | Playing with %s | Opponent: %s", board.TrumpCard, limit, g.engine.Rules.Deck, g.settings.opponent),
+	)
+	if g.engine.Rules.Transfer {
+		gameInfo += infoStyle.Render(" | Transfer variant")
+	}
+	if g.decision.Playouts > 0 {
+		gameInfo += infoStyle.Render(fmt.Sprintf(" | AI: %d playouts in %v", g.decision.Playouts, g.decision.Elapsed.Round(time.Millisecond)))
+	}
+	if eg := g.decision.Endgame; eg != nil {
+		// The AI has solved the game: show how it ends with best play.
+		seat := g.decision.Move.Seat
+		gameInfo += "\n" + statusStyle.UnsetMarginTop().Render(fmt.Sprintf("%s %s in %d moves with best play.", seatName(seat), verb(seat, eg.Outcome.String()), eg.Plies))
+	}
+
+	switch {
+	case g.hinting:
+		gameInfo += "\n" + statusStyle.UnsetMarginTop().Render("Looking for a hint...")
+	case g.hint != nil:
+		gameInfo += "\n" + statusStyle.UnsetMarginTop().Render(g.hintText())
+	}
+
+	// ===== Turn prompt =====
+	var prompt string
+	mover := board.ToMove()
+	if g.humanToMove() {
+		if board.Uncovered() == 0 {
+			if len(board.Table) > 0 {
+				prompt = "Your turn to throw in. (space/enter to play, 'p' to pass)"
+			} else {
+				prompt = "Your turn to attack."
+			}
+		} else {
+			prompt = "Your turn to defend. (space/enter to beat, 't' to take)"
+			if g.engine.Rules.Transfer {
+				prompt = "Your turn to defend. (space/enter to beat, 'r' to transfer, 't' to take)"
+			}
+		}
+	} else {
+		switch {
+		case mover == board.Defender:
+			prompt = fmt.Sprintf("%s %s defending...", seatName(mover), be(mover))
+		case len(board.Table) > 0:
+			prompt = fmt.Sprintf("%s %s throwing in...", seatName(mover), be(mover))
+		default:
+			prompt = fmt.Sprintf("%s %s attacking...", seatName(mover), be(mover))
+		}
+		if g.settings.watch {
+			prompt += " (watching the computer play your seat)"
+		}
+	}
+
+	if g.announcement != "" {
+		prompt = g.announcement + "\n" + prompt
+	}
+	status := statusStyle.Render(prompt)
+	if g.err != "" {
+		status = lipgloss.JoinVertical(lipgloss.Left, status, gameOverStyle.Render(g.err))
+	}
+	controls := infoStyle.Render("Use left/right arrows to move, '?' for a hint, 'e' to explain the AI's moves, 'q' to quit.")
+
+	// ===== Final layout =====
+	sections = append(sections,
+		lipgloss.JoinHorizontal(lipgloss.Top,
+			sectionStyle.MarginRight(4).Render(table),
+			sectionStyle.Render(deck),
+		),
+		sectionStyle.Render(player1),
+		sectionStyle.Render(gameInfo),
+		status,
+		controls,
+	)
+	view := lipgloss.JoinVertical(lipgloss.Left, sections...)
+	if g.settings.explain {
+		panelStyle := lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("240")).
+			Padding(0, 1).
+			MarginLeft(2)
+		view = lipgloss.JoinHorizontal(lipgloss.Top, view, panelStyle.Render(g.explanation(titleStyle, infoStyle)))
+	}
+	return view
+}
+
+// explanation reports on the AI's last decision: what the search found out
+// about each move and the line of play it expects.
+func (g *Game) explanation(titleStyle, infoStyle lipgloss.Style) string {
+	d := g.decision
+	if d.Move == (durak.Move{}) && d.Playouts == 0 {
+		return titleStyle.Render("The AI has not moved yet.")
+	}
+	lines := []string{titleStyle.Render(fmt.Sprintf("%s played %s", seatName(d.Move.Seat), shortMove(d.Move)))}
+	switch {
+	case d.Endgame != nil:
+		lines = append(lines, fmt.Sprintf("Endgame solved in %v:", d.Elapsed.Round(time.Millisecond)),
+			fmt.Sprintf("%s %s in %d moves.", seatName(d.Move.Seat), verb(d.Move.Seat, d.Endgame.Outcome.String()), d.Endgame.Plies))
+		return lipgloss.JoinVertical(lipgloss.Left, lines...)
+	case len(d.Moves) == 0:
+		lines = append(lines, infoStyle.Render("No search: a rule or the only move."))
+		return lipgloss.JoinVertical(lipgloss.Left, lines...)
+	}
+
+	lines = append(lines, infoStyle.Render(fmt.Sprintf("%d playouts in %v, %d reused", d.Playouts, d.Elapsed.Round(time.Millisecond), d.Reused)), "")
+	lines = append(lines, fmt.Sprintf("%-16s %6s %5s %9s", "Move", "Visits", "Win", "95%"))
+	for _, m := range d.Moves {
+		lines = append(lines, fmt.Sprintf("%-16s %6d %4.0f%% %3.0f-%3.0f%%", shortMove(m.Move), m.Visits, 100*m.WinRate, 100*m.Low, 100*m.High))
+	}
+	if d.Move != d.Moves[0].Move {
+		lines = append(lines, infoStyle.Render("It slipped on purpose."))
+	}
+	lines = append(lines, "", "Expected line:")
+	for _, move := range d.Principal {
+		label := "You"
+		if move.Seat != human {
+			label = fmt.Sprintf("C%d", move.Seat)
+		}
+		lines = append(lines, infoStyle.Render(fmt.Sprintf("  %-3s %s", label, shortMove(move))))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// shortMove puts a move in a few words without the board, e.g. "attack 9♠"
+// or "beat #2 with 10♥".
+func shortMove(m durak.Move) string {
+	switch {
+	case m.Kind == durak.MoveDefend:
+		return fmt.Sprintf("beat #%d with %s", m.Target+1, m.Card)
+	case m.Kind.HasCard():
+		return fmt.Sprintf("%s %s", m.Kind, m.Card)
+	}
+	return m.Kind.String()
+}
+
+// hintCard returns the index in the player's hand of the card the hint
+// recommends, or -1.
+func (g *Game) hintCard() int {
+	if g.hint == nil || !g.hint.Move.Kind.HasCard() {
+		return -1
+	}
+	for i, card := range g.hand() {
+		if card == g.hint.Move.Card {
+			return i
+		}
+	}
+	return -1
+}
+
+// hintText spells out the hint and how the best alternatives fare.
+func (g *Game) hintText() string {
+	hint := g.hint
+	text := "Hint: " + describe(g.board, hint.Move) + "."
+	switch {
+	case hint.Endgame != nil:
+		text += fmt.Sprintf(" You %s in %d moves with best play.", hint.Endgame.Outcome, hint.Endgame.Plies)
+	case len(hint.Moves) == 0:
+		text += " It is your only move."
+	default:
+		const alternatives = 3
+		var others []string
+		for _, stats := range hint.Moves {
+			if stats.Move == hint.Move {
+				text += fmt.Sprintf(" You get away %.0f%% of the time.", 100*stats.WinRate)
+			} else if len(others) < alternatives {
+				others = append(others, fmt.Sprintf("%s %.0f%%", describe(g.board, stats.Move), 100*stats.WinRate))
+			}
+		}
+		if len(others) > 0 {
+			text += "\nOr: " + strings.Join(others, ", ") + "."
+		}
+	}
+	return text
+}
+
+// describe puts a move of the player in words, e.g. "beat 8♥ with 10♥".
+func describe(board *durak.Board, move durak.Move) string {
+	switch move.Kind {
+	case durak.MoveAttack:
+		return fmt.Sprintf("attack with %s", move.Card)
+	case durak.MoveThrowIn:
+		return fmt.Sprintf("throw in %s", move.Card)
+	case durak.MoveDefend:
+		return fmt.Sprintf("beat %s with %s", board.Table[move.Target].Attack, move.Card)
+	case durak.MoveTransfer:
+		return fmt.Sprintf("transfer with %s", move.Card)
+	case durak.MoveTake:
+		return "take the cards"
+	case durak.MovePass:
+		return "stop throwing in"
+	}
+	return move.String()
+}
+
+// aiNames returns the names of the registered AIs.
+func aiNames() []string {
+	var names []string
+	for _, info := range durak.RegisteredAIs() {
+		names = append(names, info.Name)
+	}
+	return names
+}
+
+// ruleFlags are the command-line flags that set the rules.
+type ruleFlags struct {
+	deckSize         *int
+	minRank          *string
+	transfer         *bool
+	players          *int
+	attackLimit      *int
+	firstAttackLimit *int
+}
+
+// addRuleFlags defines the rule flags on fs.
+func addRuleFlags(fs *flag.FlagSet) ruleFlags {
+	return ruleFlags{
+		deckSize:         fs.Int("deck", 36, "deck size: 24, 36 or 52 cards"),
+		minRank:          fs.String("min-rank", "", "lowest rank in the deck, e.g. 7 (overrides -deck)"),
+		transfer:         fs.Bool("transfer", false, "play the transfer (perevodnoy) variant"),
+		players:          fs.Int("players", 2, "number of players, 2 to 6"),
+		attackLimit:      fs.Int("attack-limit", 6, "most attack cards in a round, 0 for no limit"),
+		firstAttackLimit: fs.Int("first-attack-limit", 5, "most attack cards in the first round, 0 to use -attack-limit"),
+	}
+}
+
+// rules returns the rules the flags ask for.
+func (f ruleFlags) rules() (durak.Rules, error) {
+	rules := durak.DefaultRules()
+	deck, err := durak.DeckForSize(*f.deckSize)
+	if err != nil {
+		return rules, err
+	}
+	rules.Deck = deck
+	rules.Transfer = *f.transfer
+	rules.Players = *f.players
+	rules.AttackLimit = *f.attackLimit
+	rules.FirstAttackLimit = *f.firstAttackLimit
+	if *f.minRank != "" {
+		rank, err := durak.ParseRank(*f.minRank)
+		if err != nil {
+			return rules, err
+		}
+		rules.Deck = durak.DeckSpec{MinRank: rank}
+	}
+	return rules, rules.Validate()
+}
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "tournament" {
+		os.Exit(tournament(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "train" {
+		os.Exit(train(os.Args[2:]))
+	}
+
+	ruleFlags := addRuleFlags(flag.CommandLine)
+	level := flag.String("level", "", "computer strength: easy, normal, hard or expert (asks if unset)")
+	opponent := flag.String("ai", "", "computer opponent by name, a level or a bot such as greedy (overrides -level)")
+	think := flag.Duration("think", 0, "how long the computer thinks per move, overriding -level")
+	workers := flag.Int("workers", 0, "goroutines the computer searches with, overriding -level")
+	watch := flag.Bool("watch", false, "let the computer play your seat too and watch")
+	explain := flag.Bool("explain", false, "show why the computer made its last move, as 'e' does")
+	weightsFile := flag.String("weights", "", "evaluator weights from durak train, to cut the levels' playouts short")
+	flag.Parse()
+
+	rules, err := ruleFlags.rules()
+	if err != nil {
+		fmt.Println("fatal:", err)
+		os.Exit(1)
+	}
+	settings := settings{rules: rules, opponent: durak.Hard.String(), think: *think, workers: *workers, watch: *watch, explain: *explain}
+	if *weightsFile != "" {
+		settings.weights, err = durak.LoadWeights(*weightsFile)
+		if err != nil {
+			fmt.Println("fatal:", err)
+			os.Exit(1)
+		}
+	}
+	if *level != "" {
+		l, err := durak.ParseLevel(*level)
+		if err != nil {
+			fmt.Println("fatal:", err)
+			os.Exit(1)
+		}
+		settings.opponent = l.String()
+	}
+	if *opponent != "" {
+		if _, ok := durak.LookupAI(*opponent); !ok {
+			fmt.Printf("fatal: unknown AI %q, choose from %s\n", *opponent, strings.Join(aiNames(), ", "))
+			os.Exit(1)
+		}
+		settings.opponent = *opponent
+	}
+
+	// Logging
+	f, err := LogToFile("debug.log", "debug")
+	if err != nil {
+		fmt.Println("fatal:", err)
+		os.Exit(1)
+	}
+	defer f.Close()
+
+	// Game
+	game := initialGame(settings, -1)
+	game.menu = *level == "" && *opponent == ""
+	p := tea.NewProgram(game)
+	if _, err := p.Run(); err != nil {
+		fmt.Printf("Alas, there's been an error: %v", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	durak "github.com/TESTMECS/durakgo"
+)
+
+func TestInitialGameLead(t *testing.T) {
+	rules := durak.DefaultRules()
+	rules.Players = 3
+	s := settings{rules: rules, opponent: durak.Easy.String()}
+
+	for _, previous := range []durak.Player{0, 1, 2} {
+		g := initialGame(s, previous)
+		g.cancel()
+		b := g.board
+		if b.Attacker != previous || b.Leader != previous || b.Defender != b.Next(previous) {
+			t.Errorf("after seat %d lost, seat %d attacks seat %d, want seat %d to lead", previous, b.Attacker, b.Defender, previous)
+		}
+		if !strings.Contains(g.announcement, "lost the last game") {
+			t.Errorf("after seat %d lost, the announcement is %q", previous, g.announcement)
+		}
+	}
+
+	g := initialGame(s, -1)
+	g.cancel()
+	if lead := g.engine.FirstLead(g.board); g.board.Attacker != lead.Seat {
+		t.Errorf("seat %d leads the first game, want seat %d with %v", g.board.Attacker, lead.Seat, lead.Card)
+	}
+}
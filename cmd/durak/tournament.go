@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+
+	durak "github.com/TESTMECS/durakgo"
+)
+
+// tournament runs the tournament subcommand with the arguments after its
+// name and returns the exit code.
+func tournament(args []string) int {
+	fs := flag.NewFlagSet("durak tournament", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: durak tournament [flags]")
+		fmt.Fprintln(fs.Output(), "Plays AIs against each other over seeded deals and rates them.")
+		fs.PrintDefaults()
+	}
+	ruleFlags := addRuleFlags(fs)
+	entrants := fs.String("ai", "easy,normal,greedy,hoarder,aggressive", "comma-separated AIs to enter, from "+strings.Join(aiNames(), ", ")+"; a level may be given weights of its own as level:file")
+	deals := fs.Int("deals", 100, "deals every pair of AIs plays, twice each with the seats swapped")
+	seed := fs.Uint64("seed", 1, "seed of the deals")
+	parallel := fs.Int("parallel", 1, "games played at once")
+	think := fs.Duration("think", 0, "how long the levels think per move, overriding their own")
+	workers := fs.Int("workers", 0, "goroutines the levels search with, overriding their own")
+	weightsFile := fs.String("weights", "", "evaluator weights from durak train, to cut the levels' playouts short")
+	fs.Parse(args)
+
+	rules, err := ruleFlags.rules()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "fatal:", err)
+		return 1
+	}
+	var weights *durak.Weights
+	if *weightsFile != "" {
+		weights, err = durak.LoadWeights(*weightsFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "fatal:", err)
+			return 1
+		}
+	}
+	t := &durak.Tournament{Rules: rules, Deals: *deals, Seed: *seed, Parallel: *parallel}
+	for _, entrant := range strings.Split(*entrants, ",") {
+		entrant = strings.TrimSpace(entrant)
+		name, file, _ := strings.Cut(entrant, ":")
+		info, ok := durak.LookupAI(name)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "fatal: unknown AI %q, choose from %s\n", name, strings.Join(aiNames(), ", "))
+			return 1
+		}
+		s := settings{opponent: name, think: *think, workers: *workers, weights: weights}
+		if file != "" {
+			if s.weights, err = durak.LoadWeights(file); err != nil {
+				fmt.Fprintln(os.Stderr, "fatal:", err)
+				return 1
+			}
+		}
+		info.Name = entrant
+		info.New = func(engine durak.GameEngine) durak.AI { return s.ai(engine) }
+		t.Entrants = append(t.Entrants, info)
+	}
+	t.Progress = func(done, total int) {
+		fmt.Fprintf(os.Stderr, "\r%d/%d games", done, total)
+		if done == total {
+			fmt.Fprintln(os.Stderr)
+		}
+	}
+
+	// The AIs log as they play, e.g. every slip on purpose, and a
+	// tournament plays far too many moves to keep that.
+	log.SetOutput(io.Discard)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	result, err := t.Run(ctx)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "\nfatal:", err)
+		return 1
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Match\tWins\tDraws\tLosses\tScore\t95% interval\tElo difference")
+	for _, m := range result.Matches {
+		s := m.Score
+		lo, hi := s.Interval()
+		fmt.Fprintf(w, "%s vs %s\t%d\t%d\t%d\t%s\t%s to %s\t%s (%s to %s)\n",
+			m.A, m.B, s.Wins, s.Draws, s.Losses, percent(s.Rate()), percent(lo), percent(hi),
+			elo(durak.EloDiff(s.Rate())), elo(durak.EloDiff(lo)), elo(durak.EloDiff(hi)))
+	}
+	w.Flush()
+	fmt.Println()
+	fmt.Fprintln(w, "AI\tWins\tDraws\tLosses\tScore\tElo")
+	for _, r := range result.Ratings {
+		s := r.Score
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%s\t%s\n", r.Name, s.Wins, s.Draws, s.Losses, percent(s.Rate()), elo(r.Elo))
+	}
+	w.Flush()
+	return 0
+}
+
+// percent formats a share as a percentage, e.g. "62.5%".
+func percent(x float64) string {
+	return fmt.Sprintf("%.1f%%", 100*x)
+}
+
+// elo formats a rating or a difference of ratings with its sign, e.g.
+// "+87".
+func elo(x float64) string {
+	switch {
+	case math.IsInf(x, 1):
+		return "+inf"
+	case math.IsInf(x, -1):
+		return "-inf"
+	}
+	// An even score, or one that rounds to it, shows as "+0" rather than
+	// "-0".
+	if x = math.Round(x); x == 0 {
+		x = 0
+	}
+	return fmt.Sprintf("%+.0f", x)
+}
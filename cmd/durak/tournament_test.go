@@ -0,0 +1,26 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestElo(t *testing.T) {
+	tests := []struct {
+		x    float64
+		want string
+	}{
+		{x: 87.4, want: "+87"},
+		{x: -87.6, want: "-88"},
+		{x: 0, want: "+0"},
+		{x: math.Copysign(0, -1), want: "+0"},
+		{x: -0.4, want: "+0"},
+		{x: math.Inf(1), want: "+inf"},
+		{x: math.Inf(-1), want: "-inf"},
+	}
+	for _, tt := range tests {
+		if got := elo(tt.x); got != tt.want {
+			t.Errorf("elo(%v) = %q, want %q", tt.x, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	durak "github.com/TESTMECS/durakgo"
+)
+
+// train runs the train subcommand with the arguments after its name and
+// returns the exit code.
+func train(args []string) int {
+	fs := flag.NewFlagSet("durak train", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: durak train [flags]")
+		fmt.Fprintln(fs.Output(), "Learns evaluator weights from self-play games, for -weights.")
+		fs.PrintDefaults()
+	}
+	ruleFlags := addRuleFlags(fs)
+	games := fs.Int("games", 20000, "self-play games to learn from")
+	positions := fs.Int("positions", 4, "positions picked from each game to learn from")
+	epochs := fs.Int("epochs", 20, "passes over the positions")
+	seed := fs.Uint64("seed", 1, "seed of the games and the training")
+	out := fs.String("out", "weights.json", "file to save the weights to")
+	fs.Parse(args)
+
+	rules, err := ruleFlags.rules()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "fatal:", err)
+		return 1
+	}
+	cfg := durak.TrainConfig{
+		Rules:     rules,
+		Games:     *games,
+		Positions: *positions,
+		Epochs:    *epochs,
+		Seed:      *seed,
+		Progress: func(epoch int, loss float64) {
+			fmt.Fprintf(os.Stderr, "epoch %d: held-out log loss %.4f\n", epoch, loss)
+		},
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	weights, err := durak.Train(ctx, cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "fatal:", err)
+		return 1
+	}
+	if err := weights.Save(*out); err != nil {
+		fmt.Fprintln(os.Stderr, "fatal:", err)
+		return 1
+	}
+	fmt.Println("saved", *out)
+	return 0
+}
@@ -0,0 +1,298 @@
+package durak
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// Errors returned by SolveEndgame.
+var (
+	ErrNotEndgame     = errors.New("not a perfect-information endgame")
+	ErrEndgameTooHard = errors.New("the endgame is too large to solve")
+)
+
+// Outcome is how a game ends for one player.
+type Outcome int
+
+const (
+	OutcomeLoss Outcome = iota - 1
+	OutcomeDraw
+	OutcomeWin
+)
+
+// String returns the outcome as a verb, e.g. "win".
+func (o Outcome) String() string {
+	switch o {
+	case OutcomeLoss:
+		return "lose"
+	case OutcomeDraw:
+		return "draw"
+	case OutcomeWin:
+		return "win"
+	}
+	return fmt.Sprintf("Outcome(%d)", int(o))
+}
+
+// MarshalText returns the outcome as written by String.
+func (o Outcome) MarshalText() ([]byte, error) {
+	return []byte(o.String()), nil
+}
+
+// UnmarshalText parses an outcome as written by String.
+func (o *Outcome) UnmarshalText(text []byte) error {
+	for x := OutcomeLoss; x <= OutcomeWin; x++ {
+		if string(text) == x.String() {
+			*o = x
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown outcome %q", text)
+}
+
+// EndgameResult is the value of an endgame under perfect play, for the seat
+// to move.
+type EndgameResult struct {
+	// Move is a best move for the seat to move.
+	Move Move `json:"move"`
+	// Outcome is how the game ends for the seat to move.
+	Outcome Outcome `json:"outcome"`
+	// Plies is the number of moves until the game ends. The winning side
+	// hurries, the losing side holds out.
+	Plies int `json:"plies"`
+}
+
+// String describes the result, e.g. "win in 7".
+func (r EndgameResult) String() string {
+	return fmt.Sprintf("%v in %d", r.Outcome, r.Plies)
+}
+
+// IsEndgame reports whether the board is a perfect-information endgame: the
+// deck is gone and only two players hold cards, so either can work out the
+// other's hand from the cards that have been seen.
+func IsEndgame(board *Board) bool {
+	if len(board.Deck) > 0 {
+		return false
+	}
+	holding := 0
+	for _, hand := range board.Hands {
+		if len(hand) > 0 {
+			holding++
+		}
+	}
+	return holding == 2
+}
+
+// endgameNodeLimit caps the positions SolveEndgame visits before giving up.
+const endgameNodeLimit = 1 << 21
+
+// Scores of finished games, less one per ply it takes to get there. The
+// tiers are kept apart so that a draw never outscores a win however quick.
+const (
+	scoreWin  = 3000
+	scoreDraw = 1000
+)
+
+// SolveEndgame plays out a perfect-information endgame exactly with an
+// alpha-beta search and returns its value for the seat to move. Transfers
+// can take a game round in circles; a position that comes back counts as a
+// draw, as neither side can force its way out. Positions are remembered in
+// tt, which may be kept for later calls; if tt is nil, a table is made for
+// the call. It fails with ErrNotEndgame if IsEndgame does not hold, with
+// ErrEndgameTooHard if the search grows too large and with ctx.Err() if ctx
+// is done first.
+func SolveEndgame(ctx context.Context, engine GameEngine, board *Board, tt *TranspositionTable) (EndgameResult, error) {
+	if !IsEndgame(board) {
+		return EndgameResult{}, ErrNotEndgame
+	}
+	if tt == nil {
+		tt = NewTranspositionTable(defaultEndgameTableBytes)
+	}
+	s := &endgame{
+		ctx:    ctx,
+		engine: engine,
+		seat:   board.ToMove(),
+		tt:     tt,
+		path:   make(map[uint64]bool),
+	}
+	s.path[s.key(board)] = true
+
+	// The root is searched here rather than in s.search, to keep hold of
+	// the best move.
+	result := EndgameResult{}
+	best := -scoreWin - 1
+	for _, move := range s.orderedMoves(board) {
+		next := board.Copy()
+		engine.PlayMove(next, move)
+		score := parentScore(s.search(next, childWindow(best), childWindow(scoreWin+1)))
+		if s.err != nil {
+			return EndgameResult{}, s.err
+		}
+		if score > best {
+			best = score
+			result.Move = move
+		}
+	}
+
+	switch {
+	case best >= scoreWin-scoreDraw:
+		result.Outcome, result.Plies = OutcomeWin, scoreWin-best
+	case best > 0:
+		result.Outcome, result.Plies = OutcomeDraw, scoreDraw-best
+	default:
+		result.Outcome, result.Plies = OutcomeLoss, scoreWin+best
+	}
+	return result, nil
+}
+
+// endgame is the state of one SolveEndgame call.
+type endgame struct {
+	ctx    context.Context
+	engine GameEngine
+	seat   Player // the scores are seat's
+	tt     *TranspositionTable
+	path   map[uint64]bool // the positions leading to the current one
+	nodes  int
+	err    error
+}
+
+// defaultEndgameTableBytes is the size of the table SolveEndgame makes when
+// it is given none.
+const defaultEndgameTableBytes = 16 << 20
+
+// Bounds an entry of the transposition table may hold.
+const (
+	ttExact = iota
+	ttLower
+	ttUpper
+)
+
+// key identifies a position of s. Scores are seat's, so the seat is hashed
+// in too.
+func (s *endgame) key(board *Board) uint64 {
+	return board.Hash() ^ zobrist.seat[s.seat]
+}
+
+// search returns the score of board for s.seat, searching within the window
+// (alpha, beta). Scores outside the window are bounds.
+func (s *endgame) search(board *Board, alpha, beta int) int {
+	if over, durak := s.engine.CheckGameOver(board); over {
+		switch durak {
+		case -1:
+			return scoreDraw
+		case s.seat:
+			return -scoreWin
+		}
+		return scoreWin
+	}
+
+	s.nodes++
+	if s.nodes > endgameNodeLimit {
+		s.err = ErrEndgameTooHard
+	} else if s.nodes%1024 == 0 {
+		s.err = s.ctx.Err()
+	}
+	if s.err != nil {
+		return 0
+	}
+
+	key := s.key(board)
+	if s.path[key] {
+		return scoreDraw
+	}
+	if e := s.tt.probe(key); e != nil {
+		score := int(e.score)
+		switch {
+		case e.bound == ttExact,
+			e.bound == ttLower && score >= beta,
+			e.bound == ttUpper && score <= alpha:
+			return score
+		}
+	}
+
+	moves := s.orderedMoves(board)
+	if len(moves) == 0 {
+		return 0
+	}
+	maximizing := board.ToMove() == s.seat
+	origAlpha, origBeta := alpha, beta
+	best := scoreWin + 1
+	if maximizing {
+		best = -scoreWin - 1
+	}
+	s.path[key] = true
+	defer delete(s.path, key)
+	start := s.nodes
+	for _, move := range moves {
+		next := board.Copy()
+		s.engine.PlayMove(next, move)
+		score := parentScore(s.search(next, childWindow(alpha), childWindow(beta)))
+		if s.err != nil {
+			return 0
+		}
+		if maximizing {
+			best = max(best, score)
+			alpha = max(alpha, best)
+		} else {
+			best = min(best, score)
+			beta = min(beta, best)
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+
+	bound := ttExact
+	if best <= origAlpha {
+		bound = ttUpper
+	} else if best >= origBeta {
+		bound = ttLower
+	}
+	e := s.tt.store(key)
+	e.score, e.bound = int16(best), int8(bound)
+	e.visits = int32(min(s.nodes-start, math.MaxInt32))
+	return best
+}
+
+// parentScore converts a score one ply down into one for its parent: a
+// result one move further away.
+func parentScore(score int) int {
+	switch {
+	case score > 0:
+		return score - 1
+	case score < 0:
+		return score + 1
+	}
+	return 0
+}
+
+// childWindow converts a bound of the parent's window into one for a child,
+// undoing parentScore.
+func childWindow(bound int) int {
+	switch {
+	case bound > 0:
+		return bound + 1
+	case bound < 0:
+		return bound - 1
+	}
+	return 0
+}
+
+// orderedMoves returns the legal moves with the cheapest cards first, and
+// taking last, so that good moves are searched early and cut the rest off.
+func (s *endgame) orderedMoves(board *Board) []Move {
+	moves := s.engine.GetLegalMoves(board)
+	cost := func(m Move) int {
+		switch m.Kind {
+		case MovePass:
+			return 8
+		case MoveTake:
+			return 100
+		}
+		return cardCost(board, m.Card)
+	}
+	slices.SortStableFunc(moves, func(a, b Move) int { return cost(a) - cost(b) })
+	return moves
+}
@@ -0,0 +1,136 @@
+package durak
+
+import (
+	"context"
+	"math/rand/v2"
+	"testing"
+)
+
+// bruteForce returns the score of board for seat by plain minimax over every
+// legal move, counting a position that comes back as a draw like
+// SolveEndgame does.
+func bruteForce(e *Engine, board *Board, seat Player, path map[uint64]bool) int {
+	if over, durak := e.CheckGameOver(board); over {
+		switch durak {
+		case -1:
+			return scoreDraw
+		case seat:
+			return -scoreWin
+		}
+		return scoreWin
+	}
+	key := board.Hash()
+	if path[key] {
+		return scoreDraw
+	}
+	path[key] = true
+	defer delete(path, key)
+
+	maximizing := board.ToMove() == seat
+	best := 0
+	for i, move := range e.GetLegalMoves(board) {
+		next := board.Copy()
+		e.PlayMove(next, move)
+		score := parentScore(bruteForce(e, next, seat, path))
+		if i == 0 || maximizing && score > best || !maximizing && score < best {
+			best = score
+		}
+	}
+	return best
+}
+
+// tinyEndgames plays random games through e until they reach an endgame
+// with at most six cards left, and returns those positions. The observers
+// of e see the games.
+func tinyEndgames(t *testing.T, e *Engine, n int) []*Board {
+	t.Helper()
+	rng := rand.New(rand.NewPCG(1, 2))
+	var boards []*Board
+	for len(boards) < n {
+		board := e.dealWith(rng)
+		for {
+			if over, _ := e.CheckGameOver(board); over {
+				break
+			}
+			if IsEndgame(board) && board.CardsInPlay() <= 6 {
+				boards = append(boards, board)
+				break
+			}
+			moves := e.GetLegalMoves(board)
+			if err := e.Apply(board, moves[rng.IntN(len(moves))]); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return boards
+}
+
+func TestSolveEndgameBruteForce(t *testing.T) {
+	// Without transfers no position can come back, so the value of a
+	// position does not depend on the path to it.
+	for players := 2; players <= 4; players++ {
+		rules := DefaultRules()
+		rules.Players = players
+		e := &Engine{Rules: rules}
+		tt := NewTranspositionTable(1 << 16)
+		for _, board := range tinyEndgames(t, &Engine{Rules: rules}, 30) {
+			seat := board.ToMove()
+			got, err := SolveEndgame(context.Background(), e, board, tt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := bruteForce(e, board, seat, map[uint64]bool{})
+			next := board.Copy()
+			e.PlayMove(next, got.Move)
+			if moved := parentScore(bruteForce(e, next, seat, map[uint64]bool{board.Hash(): true})); moved != want {
+				t.Errorf("%d players, %v: best move %v scores %d, brute force finds %d", players, board.Hands, got.Move, moved, want)
+			}
+			var score int
+			switch got.Outcome {
+			case OutcomeWin:
+				score = scoreWin - got.Plies
+			case OutcomeDraw:
+				score = scoreDraw - got.Plies
+			default:
+				score = -scoreWin + got.Plies
+			}
+			if score != want {
+				t.Errorf("%d players, %v: SolveEndgame = %v, brute force scores %d", players, board.Hands, got, want)
+			}
+		}
+	}
+}
+
+func TestNoCardTrackingSkipsEndgame(t *testing.T) {
+	for _, tracking := range []bool{true, false} {
+		engine := &Engine{Rules: DefaultRules()}
+		ai := NewMCTS(engine, MCTSConfig{Budget: Budget{Iterations: 10}, Workers: 1, Seed: 1, NoCardTracking: !tracking})
+		engine.SetAI(ai)
+		board := tinyEndgames(t, engine, 1)[0]
+		decision := ai.Solve(context.Background(), board.Copy())
+		if solved := decision.Endgame != nil; solved != tracking {
+			t.Errorf("with card tracking %v, the endgame solved: %v", tracking, solved)
+		}
+	}
+}
+
+func TestJoiningLateSkipsEndgame(t *testing.T) {
+	// The AI joins in the third round, so it missed cards being discarded
+	// and cannot know the other hand once the deck is gone.
+	engine := &Engine{Rules: DefaultRules()}
+	ai := NewMCTS(engine, MCTSConfig{Budget: Budget{Iterations: 10}, Workers: 1, Seed: 1})
+	rng := rand.New(rand.NewPCG(1, 2))
+	board := engine.dealWith(rng)
+	for !IsEndgame(board) || board.CardsInPlay() > 12 {
+		if board.Round == 2 {
+			engine.SetAI(ai)
+		}
+		moves := engine.GetLegalMoves(board)
+		if err := engine.Apply(board, moves[rng.IntN(len(moves))]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if decision := ai.Solve(context.Background(), board.Copy()); decision.Endgame != nil {
+		t.Errorf("an AI that joined late solved the endgame: %v", decision.Endgame)
+	}
+}
@@ -1,187 +1,426 @@
-package main
+// Package durak implements the rules of the card game Durak together with a
+// Monte Carlo tree search opponent.
+package durak
 
-import "log"
+import (
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"math"
+	"math/rand/v2"
+	"reflect"
+	"slices"
+)
 
-type Move struct {
-	Card []Card
-	take bool
+// HandSize is the number of cards players refill their hands to.
+const HandSize = 6
+
+// Rules are the house rules a game is played with.
+type Rules struct {
+	// Deck is the deck the game is dealt from.
+	Deck DeckSpec
+	// Players is the number of seats at the table, from 2 to 6.
+	Players int
+	// Transfer enables the "perevodnoy" variant, where a defender may pass
+	// the attack on by adding a card of the same rank.
+	Transfer bool
+	// AttackLimit caps the attack cards in a round. Zero means no cap
+	// beyond the defender's hand.
+	AttackLimit int
+	// FirstAttackLimit caps the attack cards in the first round of the
+	// game. Zero means AttackLimit applies.
+	FirstAttackLimit int
+}
+
+// DefaultRules returns the rules of classic Durak: at most 6 attack cards a
+// round, and 5 in the first round.
+func DefaultRules() Rules {
+	return Rules{Deck: Deck36, Players: 2, AttackLimit: 6, FirstAttackLimit: 5}
 }
-type Deck struct {
-	Cards []Card
-	trump Suit
+
+// Validate reports whether a game can be dealt under the rules.
+func (r Rules) Validate() error {
+	if r.Deck.MinRank < Two || r.Deck.MinRank > Ace {
+		return fmt.Errorf("invalid minimum rank %d", r.Deck.MinRank)
+	}
+	if r.Players < 2 || r.Players > 6 {
+		return fmt.Errorf("durak is played by 2 to 6 players, not %d", r.Players)
+	}
+	if r.AttackLimit < 0 || r.FirstAttackLimit < 0 {
+		return fmt.Errorf("attack limits cannot be negative")
+	}
+	if r.Deck.Size() <= r.Players*HandSize {
+		return fmt.Errorf("a %s deck is too small to deal %d hands of %d", r.Deck, r.Players, HandSize)
+	}
+	return nil
 }
+
+// Engine applies the rules of Durak to a Board.
 type Engine struct {
-	PlayerHand   []Card
-	OpponentHand []Card
-	Table        []TableCards
-	Deck         []Card
-	TrumpSuit    Suit
-	Attacker     Player
-	AI           AI
-}
-
-func NewEngine() *Engine {
-	engine := &Engine{}
-	engine.AI = NewMCTS(engine, 100)
+	Rules Rules
+	// AI plays every seat that has no AI of its own in Seats.
+	AI AI
+	// Seats holds the AIs of seats played differently, e.g. to let two AIs
+	// play each other. Use SetSeatAI to change it.
+	Seats map[Player]AI
+	// Observers are told about every game dealt and every move made
+	// through Apply, as are the AIs that implement Observer.
+	Observers []Observer
+}
+
+// NewEngine returns an Engine for the given rules whose AI is a Monte Carlo
+// tree search at the Normal level. The AI observes the game.
+func NewEngine(rules Rules) *Engine {
+	engine := &Engine{Rules: rules}
+	engine.SetAI(NewMCTS(engine, Normal.Config()))
 	return engine
 }
 
-// Copy returns a copy of the board for easy modification
-func (b *Board) Copy() *Board {
-	newB := &Board{
-		PlayerHand:   make([]Card, len(b.PlayerHand)),
-		OpponentHand: make([]Card, len(b.OpponentHand)),
-		Table:        make([]TableCards, len(b.Table)),
-		TrumpSuit:    b.TrumpSuit,
-		Attacker:     b.Attacker,
-		Deck:         make([]Card, len(b.Deck)),
+// SetAI replaces the AI. If it is an Observer it observes the game in place
+// of the previous AI.
+func (e *Engine) SetAI(ai AI) {
+	e.AI = ai
+}
+
+// SetSeatAI lets ai play seat p instead of the engine's AI. A nil ai hands
+// the seat back to it. If ai is an Observer it observes the game as with
+// SetAI.
+func (e *Engine) SetSeatAI(p Player, ai AI) {
+	if e.Seats == nil {
+		e.Seats = make(map[Player]AI)
+	}
+	if ai == nil {
+		delete(e.Seats, p)
+	} else {
+		e.Seats[p] = ai
+	}
+}
+
+// AIFor returns the AI that plays seat p.
+func (e *Engine) AIFor(p Player) AI {
+	if ai, ok := e.Seats[p]; ok {
+		return ai
+	}
+	return e.AI
+}
+
+// observers returns who is told about the game: the Observers, then every
+// AI playing a seat that is an Observer. An AI playing several seats is told
+// once. AIs whose values cannot be compared, e.g. a struct holding a slice,
+// cannot be told apart and are told once per seat.
+func (e *Engine) observers() []Observer {
+	observers := slices.Clone(e.Observers)
+	add := func(ai AI) {
+		o, ok := ai.(Observer)
+		if ok && !slices.ContainsFunc(observers, func(x Observer) bool { return sameObserver(x, o) }) {
+			observers = append(observers, o)
+		}
+	}
+	add(e.AI)
+	for _, p := range slices.Sorted(maps.Keys(e.Seats)) {
+		add(e.Seats[p])
+	}
+	return observers
+}
+
+// sameObserver reports whether a and b are the same observer. Unlike ==, it
+// does not panic on values that cannot be compared; they are never the same.
+func sameObserver(a, b Observer) bool {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	return va.Type() == vb.Type() && va.Comparable() && a == b
+}
+
+// NewBoard shuffles a fresh deck and deals a new game. The bottom card of the
+// deck is turned face up and decides the trump suit, and the first attacker
+// is chosen by FirstLead.
+func (e *Engine) NewBoard() *Board {
+	deck := e.Rules.Deck.Cards()
+	ShuffleDeck(deck)
+	return e.Deal(deck)
+}
+
+// Deal deals a new game from deck as it is ordered, like NewBoard does
+// after shuffling: the hands come off the top and the last card is trump.
+// It lets a deal be replayed, e.g. from a seeded shuffle. The board takes
+// ownership of deck. The observers are told about the new game.
+func (e *Engine) Deal(deck []Card) *Board {
+	trumpCard := deck[len(deck)-1]
+
+	board := &Board{
+		Hands:     make([][]Card, e.Rules.Players),
+		TrumpSuit: trumpCard.Suit,
+		TrumpCard: trumpCard,
+	}
+	for i := range board.Hands {
+		board.Hands[i], deck = deck[:HandSize:HandSize], deck[HandSize:]
+	}
+	board.Deck = deck
+	board.SetAttacker(e.FirstLead(board).Seat)
+	for _, o := range e.observers() {
+		o.NewGame(board)
+	}
+	return board
+}
+
+// dealWith deals a new game from a deck shuffled by rng, so that the deal
+// can be repeated.
+func (e *Engine) dealWith(rng *rand.Rand) *Board {
+	deck := e.Rules.Deck.Cards()
+	rng.Shuffle(len(deck), func(i, j int) { deck[i], deck[j] = deck[j], deck[i] })
+	return e.Deal(deck)
+}
+
+// Lead tells who opens a game and why.
+type Lead struct {
+	// Seat is the first attacker.
+	Seat Player
+	// Card is the card that decided the lead.
+	Card Card
+	// Trump is false if nobody was dealt a trump and the lowest card decided
+	// instead.
+	Trump bool
+}
+
+// FirstLead applies the standard rule for the first attack: the player
+// holding the lowest trump leads. If nobody holds a trump, the player with the
+// lowest card leads, ties going to the lower suit.
+func (e *Engine) FirstLead(board *Board) Lead {
+	var lead Lead
+	found := false
+	for p, hand := range board.Hands {
+		for _, card := range hand {
+			isTrump := card.Suit == board.TrumpSuit
+			better := !found ||
+				(isTrump && !lead.Trump) ||
+				(isTrump == lead.Trump && (card.Rank < lead.Card.Rank ||
+					card.Rank == lead.Card.Rank && card.Suit < lead.Card.Suit))
+			if better {
+				lead = Lead{Seat: Player(p), Card: card, Trump: isTrump}
+				found = true
+			}
+		}
 	}
-	copy(newB.PlayerHand, b.PlayerHand)
-	copy(newB.OpponentHand, b.OpponentHand)
-	copy(newB.Table, b.Table)
-	copy(newB.Deck, b.Deck)
-	return newB
+	return lead
 }
 
-func (e *Engine) AITurn(board *Board) {
-	bestMove := e.AI.Solve(board.Copy())
-	log.Println("AI Move:", bestMove)
-	e.PlayMove(board, bestMove)
+// AITurn lets the AI of the seat to move choose a move and plays it on the
+// board, and returns the decision for the caller to show or log. A move the
+// engine rejects is logged and replaced by the first legal move.
+func (e *Engine) AITurn(ctx context.Context, board *Board) Decision {
+	decision := e.AIFor(board.ToMove()).Solve(ctx, board.Copy())
+	if err := e.Apply(board, decision.Move); err != nil {
+		log.Println("AI:", err)
+		if moves := e.GetLegalMoves(board); len(moves) > 0 {
+			e.Apply(board, moves[0])
+		}
+	}
+	return decision
 }
 
-// GetLegalMoves returns all legal moves for the current player.
+// GetLegalMoves returns all legal moves for the player to move.
 func (e *Engine) GetLegalMoves(board *Board) []Move {
 	var moves []Move
-	hand := board.OpponentHand // AI's hand
-
-	isAIAttacking := board.Attacker == 1
+	mover := board.ToMove()
+	if mover < 0 {
+		return nil
+	}
+	hand := board.Hand(mover)
 
-	if isAIAttacking { // AI is Attacking
+	if board.Uncovered() == 0 { // Throwers' turn
 		if len(board.Table) == 0 {
 			// Can play any card to start attack
 			for _, card := range hand {
-				moves = append(moves, Move{Card: []Card{card}})
+				moves = append(moves, Move{Kind: MoveAttack, Seat: mover, Card: card})
 			}
 		} else {
-			// Can add any card with the same rank as cards on the table
-			tableRanks := make(map[Rank]bool)
-			for _, tableCard := range board.Table {
-				tableRanks[tableCard.c.Rank] = true
+			// Can throw in any card with the same rank as cards on the table,
+			// up to the attack limit.
+			if e.canThrowIn(board) {
+				tableRanks := board.TableRanks()
+				for _, card := range hand {
+					if tableRanks[card.Rank] {
+						moves = append(moves, Move{Kind: MoveThrowIn, Seat: mover, Card: card})
+					}
+				}
+			}
+			// Or leave the attack to the next thrower
+			moves = append(moves, Move{Kind: MovePass, Seat: mover})
+		}
+	} else { // Defender's turn
+		for i, tc := range board.Table {
+			if tc.Covered {
+				continue
 			}
 			for _, card := range hand {
-				if tableRanks[card.Rank] {
-					moves = append(moves, Move{Card: []Card{card}})
+				if e.CanBeat(tc.Attack, card, board.TrumpSuit) {
+					moves = append(moves, Move{Kind: MoveDefend, Seat: mover, Card: card, Target: i})
 				}
 			}
-			// "take" move is to pass and end the attack round
-			moves = append(moves, Move{take: true})
 		}
-	} else { // AI is Defending
-		if len(board.Table) > 0 {
-			attackingCard := board.Table[len(board.Table)-1].c
+		if e.canTransfer(board) {
+			rank := board.Table[0].Attack.Rank
 			for _, card := range hand {
-				if e.CanBeat(attackingCard, card, board.TrumpSuit) {
-					moves = append(moves, Move{Card: []Card{card}})
+				if card.Rank == rank {
+					moves = append(moves, Move{Kind: MoveTransfer, Seat: mover, Card: card})
 				}
 			}
 		}
-		// "take" move is to take cards
-		moves = append(moves, Move{take: true})
+		moves = append(moves, Move{Kind: MoveTake, Seat: mover})
 	}
 
 	return moves
 }
 
-// CanBeat checks if a defending card can beat an attacking card.
-// Bug here
+// CanBeat checks if a defending card can beat an attacking card: a higher
+// card of the same suit, or any trump against a plain suit.
 func (e *Engine) CanBeat(attack Card, defense Card, trump Suit) bool {
+	return beats(attack, defense, trump)
+}
+
+func beats(attack Card, defense Card, trump Suit) bool {
 	if attack.Suit == defense.Suit {
 		return defense.Rank > attack.Rank
 	}
-	if attack.Suit == trump {
-		return defense.Suit != trump
-	}
-	if defense.Suit == trump {
-		return attack.Suit != trump
-	}
-	return false
+	return defense.Suit == trump
 }
 
-// DrawCards refills players' hands from the deck up to 6 cards.
+// DrawCards refills players' hands from the deck up to HandSize cards. The
+// seat that opened the round draws first, even if a transfer has since
+// turned the attack around, then the others clockwise and the defender
+// last. Cards come off the front of the deck, so the face-up trump card at
+// its end is drawn last.
 func (e *Engine) DrawCards(board *Board) {
-	for len(board.PlayerHand) < 6 && len(board.Deck) > 0 {
-		board.PlayerHand = append(board.PlayerHand, board.Deck[0])
-		board.Deck = board.Deck[1:]
+	n := Player(board.Players())
+	order := make([]Player, 0, n)
+	for i := Player(0); i < n; i++ {
+		if p := (board.Leader + i) % n; p != board.Defender {
+			order = append(order, p)
+		}
 	}
-	for len(board.OpponentHand) < 6 && len(board.Deck) > 0 {
-		board.OpponentHand = append(board.OpponentHand, board.Deck[0])
-		board.Deck = board.Deck[1:]
+	order = append(order, board.Defender)
+
+	for _, p := range order {
+		for len(board.Hands[p]) < HandSize && len(board.Deck) > 0 {
+			board.Hands[p] = append(board.Hands[p], board.Deck[0])
+			board.Deck = board.Deck[1:]
+		}
 	}
 }
 
+// PlayMove applies a move for the player to move without checking it. Use
+// Apply for moves that have not been generated by GetLegalMoves.
 func (e *Engine) PlayMove(board *Board, move Move) {
-	if move.take {
-		if board.Attacker == 1 { // AI is attacker and passes
-			board.Attacker = e.GetOpponent(board.Attacker)
-			board.Table = []TableCards{} // Round ends, cards are discarded
-		} else { // AI is defender and takes
-			if len(board.Table) > 0 {
-				for _, tc := range board.Table {
-					board.OpponentHand = append(board.OpponentHand, tc.c)
-				}
-				board.Table = []TableCards{}
-			}
-		}
-		e.DrawCards(board)
+	mover := board.ToMove()
+	if mover < 0 {
 		return
 	}
+	card := move.Card
+	defender := board.Defender
 
-	card := move.Card[0]
-	if board.Attacker == 1 { // AI is Attacker
-		board.Table = append(board.Table, TableCards{c: card})
-		board.OpponentHand = removeCard(board.OpponentHand, card)
-		// Attacker does not change, turn passes to player to defend.
-	} else { // AI is Defender
-		if len(board.Table) > 0 {
-			attackingCard := board.Table[len(board.Table)-1].c
-			if e.CanBeat(attackingCard, card, board.TrumpSuit) {
-				// Successful defense, round ends.
-				board.Table = []TableCards{}
-				board.OpponentHand = removeCard(board.OpponentHand, card)
-				e.DrawCards(board)
-				board.Attacker = e.GetOpponent(board.Attacker) // AI becomes attacker
-			} else {
-				// Invalid move, treat as taking cards.
-				for _, tc := range board.Table {
-					board.OpponentHand = append(board.OpponentHand, tc.c)
-				}
-				board.Table = []TableCards{}
-				e.DrawCards(board)
-			}
+	switch move.Kind {
+	case MovePass: // Every attack is beaten, the next thrower may go on
+		board.Passes++
+		e.settle(board)
+		return
+	case MoveTake:
+		e.endRound(board, true)
+		return
+	case MoveTransfer:
+		if !e.canTransfer(board) || card.Rank != board.Table[0].Attack.Rank {
+			// Invalid move, treat as taking cards.
+			e.endRound(board, true)
+			return
 		}
+		// The defender joins the attack and the attack passes on.
+		board.Table = append(board.Table, TableCards{Attack: card})
+		board.Hands[defender] = removeCard(board.Hands[defender], card)
+		board.Attacker = defender
+		board.Defender = board.Next(defender)
+		board.Passes = 0
+		return
+	case MoveAttack, MoveThrowIn:
+		board.Table = append(board.Table, TableCards{Attack: card})
+		board.Hands[mover] = removeCard(board.Hands[mover], card)
+		board.Passes = 0
+		return
+	}
+
+	// Defender covers the targeted attack
+	if move.Target < 0 || move.Target >= len(board.Table) || board.Table[move.Target].Covered ||
+		!e.CanBeat(board.Table[move.Target].Attack, card, board.TrumpSuit) {
+		// Invalid move, treat as taking cards.
+		e.endRound(board, true)
+		return
+	}
+	board.Table[move.Target].Defense = card
+	board.Table[move.Target].Covered = true
+	board.Hands[defender] = removeCard(board.Hands[defender], card)
+	// Every thrower gets a new chance to throw in, starting with the
+	// attacker.
+	board.Passes = 0
+	e.settle(board)
+}
+
+// settle ends the round once every attack is beaten and nobody can throw in
+// anymore: every thrower passed, none has cards left or the defender has
+// nothing left to cover with.
+func (e *Engine) settle(board *Board) {
+	if len(board.Table) == 0 || board.Uncovered() > 0 {
+		return
+	}
+	if board.Passes >= len(board.Throwers()) || !e.canThrowIn(board) {
+		e.endRound(board, false)
+	}
+}
+
+// AttackLimit returns how many attack cards the current round may hold.
+func (e *Engine) AttackLimit(board *Board) int {
+	limit := e.Rules.AttackLimit
+	if board.Round == 0 && e.Rules.FirstAttackLimit > 0 {
+		limit = e.Rules.FirstAttackLimit
 	}
+	if limit <= 0 {
+		limit = math.MaxInt
+	}
+	return limit
 }
 
-func (e *Engine) GetOpponent(player Player) Player {
-	if player == 0 {
-		return 1
+// endRound clears the table and refills the hands. If the defender took the
+// cards they lose their turn to attack, otherwise they lead the next round.
+func (e *Engine) endRound(board *Board, taken bool) {
+	if taken {
+		board.Hands[board.Defender] = append(board.Hands[board.Defender], board.TableCardList()...)
+	}
+	board.Table = []TableCards{} // Beaten cards are discarded
+	board.Round++
+	e.DrawCards(board)
+
+	next := board.Defender
+	if taken || !board.InGame(next) {
+		next = board.Next(board.Defender)
 	}
-	return 0
+	board.SetAttacker(next)
 }
 
+// CheckGameOver reports whether the game has ended and who lost. The game
+// ends between rounds once the deck is gone and at most one player still
+// holds cards; that player is the durak. The durak is -1 for a draw.
 func (e *Engine) CheckGameOver(board *Board) (bool, Player) {
-	if len(board.Deck) == 0 {
-		if len(board.PlayerHand) == 0 && len(board.OpponentHand) == 0 {
-			return true, -1 // Draw
-		}
-		if len(board.PlayerHand) == 0 {
-			return true, 0 // Player wins
+	if len(board.Deck) > 0 || len(board.Table) > 0 {
+		return false, -1
+	}
+	durak := Player(-1)
+	for p, hand := range board.Hands {
+		if len(hand) == 0 {
+			continue
 		}
-		if len(board.OpponentHand) == 0 {
-			return true, 1 // AI wins
+		if durak >= 0 {
+			return false, -1
 		}
+		durak = Player(p)
 	}
-	return false, -1
+	return true, durak
 }
 
 // removeCard is a helper function to remove a card from a hand.
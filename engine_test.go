@@ -0,0 +1,259 @@
+package durak
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestEndRound(t *testing.T) {
+	rules := Rules{Deck: Deck36, Players: 3, AttackLimit: 6}
+	transfer := rules
+	transfer.Transfer = true
+
+	tests := []struct {
+		name  string
+		rules Rules
+		hands []string
+		deck  string
+		moves []string
+		// want holds every hand once the moves are played.
+		want     []string
+		attacker Player
+		defender Player
+	}{
+		{
+			name:  "draw after bito",
+			rules: rules,
+			hands: []string{"9♥ 10♣ J♣ Q♣ K♣ A♣", "10♥ 6♣ 7♣ 8♣ 9♣ 6♥", "A♦ K♦ Q♦ J♦ 10♦"},
+			deck:  "6♦ 7♦ 8♠",
+			moves: []string{"0 attack 9♥", "1 defend 10♥ 0", "0 pass", "2 pass"},
+			// The attacker draws first, then clockwise and the defender
+			// last.
+			want:     []string{"10♣ J♣ Q♣ K♣ A♣ 6♦", "6♣ 7♣ 8♣ 9♣ 6♥ 8♠", "A♦ K♦ Q♦ J♦ 10♦ 7♦"},
+			attacker: 1,
+			defender: 2,
+		},
+		{
+			name:     "draw after a take",
+			rules:    rules,
+			hands:    []string{"9♥ 10♣ J♣ Q♣ K♣ A♣", "10♥ 6♣ 7♣ 8♣ 9♣ 6♥", "A♦ K♦ Q♦ J♦ 10♦"},
+			deck:     "6♦ 7♦ 8♠",
+			moves:    []string{"0 attack 9♥", "1 take"},
+			want:     []string{"10♣ J♣ Q♣ K♣ A♣ 6♦", "10♥ 6♣ 7♣ 8♣ 9♣ 6♥ 9♥", "A♦ K♦ Q♦ J♦ 10♦ 7♦"},
+			attacker: 2,
+			defender: 0,
+		},
+		{
+			name:  "draw after a transfer",
+			rules: transfer,
+			hands: []string{"9♥ J♣ Q♣ K♣ A♣ A♥", "9♣ 6♣ 7♣ 8♣ 6♥ 7♥", "10♥ 10♣ A♦ K♦ Q♦ J♦"},
+			deck:  "6♦ 7♦ 8♠",
+			moves: []string{"0 attack 9♥", "1 transfer 9♣", "2 defend 10♥ 0", "2 defend 10♣ 1", "1 pass", "0 pass"},
+			// Seat 0 opened the round and draws before seat 1, who
+			// attacked once the attack passed on.
+			want:     []string{"J♣ Q♣ K♣ A♣ A♥ 6♦", "6♣ 7♣ 8♣ 6♥ 7♥ 7♦", "A♦ K♦ Q♦ J♦ 8♠"},
+			attacker: 2,
+			defender: 0,
+		},
+		{
+			name:     "defender out after bito",
+			rules:    rules,
+			hands:    []string{"9♥ 10♣", "10♥", "J♦"},
+			moves:    []string{"0 attack 9♥", "1 defend 10♥ 0"},
+			want:     []string{"10♣", "", "J♦"},
+			attacker: 2,
+			defender: 0,
+		},
+		{
+			name:     "next seat out after a take",
+			rules:    Rules{Deck: Deck36, Players: 4, AttackLimit: 6},
+			hands:    []string{"9♥ 10♣", "6♣", "", "J♦"},
+			moves:    []string{"0 attack 9♥", "1 take"},
+			want:     []string{"10♣", "6♣ 9♥", "", "J♦"},
+			attacker: 3,
+			defender: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Engine{Rules: tt.rules}
+			b := testBoard(t, tt.hands...)
+			b.Deck = cards(t, tt.deck)
+			for _, s := range tt.moves {
+				move, err := ParseMove(s)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := e.Apply(b, move); err != nil {
+					t.Fatalf("Apply(%v): %v", move, err)
+				}
+			}
+			if b.Round != 2 || len(b.Table) != 0 {
+				t.Fatalf("round %d with %d slots on the table, want the round over", b.Round, len(b.Table))
+			}
+			for p, h := range tt.want {
+				if want := cards(t, h); !slices.Equal(b.Hands[p], want) {
+					t.Errorf("seat %d holds %v, want %v", p, b.Hands[p], want)
+				}
+			}
+			if b.Attacker != tt.attacker || b.Defender != tt.defender {
+				t.Errorf("seat %d attacks seat %d, want %d attacking %d", b.Attacker, b.Defender, tt.attacker, tt.defender)
+			}
+		})
+	}
+}
+
+func TestCheckGameOver(t *testing.T) {
+	tests := []struct {
+		name  string
+		hands []string
+		deck  string
+		table bool
+		over  bool
+		durak Player
+	}{
+		{name: "last player holding cards", hands: []string{"", "9♥ 10♣", ""}, over: true, durak: 1},
+		{name: "draw", hands: []string{"", "", ""}, over: true, durak: -1},
+		{name: "two players holding cards", hands: []string{"9♥", "", "J♦"}, durak: -1},
+		{name: "deck left", hands: []string{"", "9♥", ""}, deck: "6♦ 7♦ 8♠", durak: -1},
+		{name: "round under way", hands: []string{"", "9♥", ""}, table: true, durak: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Engine{Rules: Rules{Deck: Deck36, Players: len(tt.hands)}}
+			b := testBoard(t, tt.hands...)
+			b.Deck = cards(t, tt.deck)
+			if tt.table {
+				b.Table = []TableCards{{Attack: cards(t, "6♥")[0]}}
+			}
+			if over, durak := e.CheckGameOver(b); over != tt.over || durak != tt.durak {
+				t.Errorf("CheckGameOver = %v, %d, want %v, %d", over, durak, tt.over, tt.durak)
+			}
+		})
+	}
+}
+
+// sliceBot is an AI and Observer with value receivers whose values cannot be
+// compared. It counts the games it is told about in games.
+type sliceBot struct {
+	prefs []int
+	games *int
+}
+
+func (b sliceBot) Solve(ctx context.Context, board *Board) Decision {
+	return Decision{Move: Move{Kind: MoveTake, Seat: board.ToMove()}}
+}
+
+func (b sliceBot) NewGame(board *Board)                           { *b.games++ }
+func (b sliceBot) Observe(before *Board, move Move, after *Board) {}
+
+// countingBot is sliceBot behind a pointer, so that it can be compared.
+type countingBot struct{ sliceBot }
+
+func TestObservers(t *testing.T) {
+	e := &Engine{Rules: DefaultRules()}
+	var replaced, bot, seats int
+	e.SetAI(sliceBot{games: &replaced})
+	e.SetAI(sliceBot{prefs: []int{1}, games: &bot})
+	e.SetAI(sliceBot{prefs: []int{1}, games: &bot})
+	shared := &countingBot{sliceBot{games: &seats}}
+	e.SetSeatAI(0, shared)
+	e.SetSeatAI(1, shared)
+
+	e.NewBoard()
+	if replaced != 0 {
+		t.Errorf("a replaced AI was told about %d games, want 0", replaced)
+	}
+	if bot != 1 {
+		t.Errorf("the AI was told about %d games, want 1", bot)
+	}
+	if seats != 1 {
+		t.Errorf("an AI playing two seats was told about %d games, want 1", seats)
+	}
+
+	e.SetSeatAI(0, nil)
+	e.SetSeatAI(1, nil)
+	e.NewBoard()
+	if seats != 1 {
+		t.Errorf("an AI handed its seats back was told about %d games, want 1", seats)
+	}
+}
+
+func TestFirstLead(t *testing.T) {
+	// Spades are trump.
+	tests := []struct {
+		name  string
+		hands []string
+		want  string
+		seat  Player
+		trump bool
+	}{
+		{name: "lowest trump", hands: []string{"6♥ 9♠ A♦", "7♣ 7♠", "6♣ K♠"}, want: "7♠", seat: 1, trump: true},
+		{name: "trump over lower plain cards", hands: []string{"6♥ 6♦", "A♠ 7♣"}, want: "A♠", seat: 1, trump: true},
+		{name: "no trumps", hands: []string{"9♥ 10♣", "7♦ Q♥", "8♣ J♦"}, want: "7♦", seat: 1},
+		{name: "equal ranks", hands: []string{"7♥ J♣", "7♦ Q♥", "7♣ 9♦"}, want: "7♣", seat: 2},
+		{name: "equal ranks in one hand", hands: []string{"8♥ 8♦ K♣", "9♣ 10♦"}, want: "8♦", seat: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Engine{Rules: Rules{Deck: Deck36, Players: len(tt.hands)}}
+			want := Lead{Seat: tt.seat, Card: cards(t, tt.want)[0], Trump: tt.trump}
+			if got := e.FirstLead(testBoard(t, tt.hands...)); got != want {
+				t.Errorf("FirstLead = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestRulesValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules Rules
+		ok    bool
+	}{
+		{name: "default", rules: DefaultRules(), ok: true},
+		{name: "three players, 24 cards", rules: Rules{Deck: Deck24, Players: 3}, ok: true},
+		{name: "four players, 24 cards", rules: Rules{Deck: Deck24, Players: 4}},
+		{name: "six players, 36 cards", rules: Rules{Deck: Deck36, Players: 6}},
+		{name: "six players, 52 cards", rules: Rules{Deck: Deck52, Players: 6}, ok: true},
+		{name: "one player", rules: Rules{Deck: Deck36, Players: 1}},
+		{name: "negative limit", rules: Rules{Deck: Deck36, Players: 2, AttackLimit: -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.rules.Validate(); (err == nil) != tt.ok {
+				t.Errorf("Validate = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestFirstRoundAttackLimit(t *testing.T) {
+	e := &Engine{Rules: DefaultRules()}
+	b := testBoard(t, "9♣ A♦", "Q♣ K♣ A♣ Q♦ K♦ A♥")
+	for _, pair := range []string{"9♥ 10♥", "9♦ 10♦", "6♥ 8♥", "6♣ 7♣", "7♥ J♥"} {
+		c := cards(t, pair)
+		b.Table = append(b.Table, TableCards{Attack: c[0], Defense: c[1], Covered: true})
+	}
+	throwIn := Move{Kind: MoveThrowIn, Seat: 0, Card: cards(t, "9♣")[0]}
+
+	// Five attacks fill the first round, so 9♣ may not follow.
+	b.Round = 0
+	if limit := e.AttackLimit(b); limit != 5 {
+		t.Errorf("AttackLimit in the first round = %d, want 5", limit)
+	}
+	if moves := e.GetLegalMoves(b); slices.Contains(moves, throwIn) {
+		t.Errorf("a sixth attack is offered in the first round: %v", moves)
+	}
+
+	b.Round = 1
+	if limit := e.AttackLimit(b); limit != 6 {
+		t.Errorf("AttackLimit after the first round = %d, want 6", limit)
+	}
+	if moves := e.GetLegalMoves(b); !slices.Contains(moves, throwIn) {
+		t.Errorf("%v is not offered after the first round: %v", throwIn, moves)
+	}
+}
@@ -0,0 +1,166 @@
+package durak
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+)
+
+// Evaluator estimates how a game will end without playing it out. The
+// search calls it from several goroutines at once.
+type Evaluator interface {
+	// Evaluate returns the chance that seat does not end up the durak, a
+	// draw counting half.
+	Evaluate(board *Board, seat Player) float64
+}
+
+// WeightsVersion is the version of the weights file format written by
+// Weights.Save. It changes whenever the features do.
+const WeightsVersion = 1
+
+// featureNames name the features of a position, in order. They are saved
+// with the weights, so that weights trained on other features are refused.
+var featureNames = []string{
+	"hand",              // cards in hand, in hands of HandSize
+	"hand-difference",   // cards in hand less the opponents' average
+	"trumps",            // trumps in hand
+	"low-cards",         // plain cards below a ten in hand
+	"strength",          // average cardCost of the hand
+	"deck",              // cards left in the deck, in 36 card decks
+	"opponent-trumps",   // trumps per opponent
+	"opponent-strength", // average cardCost of the opponents' cards
+	"attacking",         // 1 for the attacker
+	"defending",         // 1 for the defender
+	"table",             // cards the defender would pick up, for the defender
+	"opponents",         // players other than seat still in the game
+}
+
+// features describes the position from seat's side as numbers of about the
+// same scale. The opponents' cards are read off the board: in the search
+// they are dealt in line with what seat knows, so the cards an opponent is
+// known to hold count in full and the rest as guesses.
+func features(board *Board, seat Player) []float64 {
+	f := make([]float64, len(featureNames))
+	hand := board.Hands[seat]
+	f[0] = float64(len(hand)) / HandSize
+
+	trumps := func(cards []Card) (n int) {
+		for _, card := range cards {
+			if card.Suit == board.TrumpSuit {
+				n++
+			}
+		}
+		return n
+	}
+	strength := func(cards []Card) (sum int) {
+		for _, card := range cards {
+			sum += cardCost(board, card)
+		}
+		return sum
+	}
+
+	f[2] = float64(trumps(hand))
+	for _, card := range hand {
+		if card.Suit != board.TrumpSuit && card.Rank < Ten {
+			f[3]++
+		}
+	}
+	if len(hand) > 0 {
+		f[4] = float64(strength(hand)) / float64(len(hand)) / 32
+	}
+	f[5] = float64(len(board.Deck)) / 36
+
+	opponents, cards, oppTrumps, oppStrength := 0, 0, 0, 0
+	for p, other := range board.Hands {
+		if Player(p) == seat || len(other) == 0 && len(board.Deck) == 0 {
+			continue
+		}
+		opponents++
+		cards += len(other)
+		oppTrumps += trumps(other)
+		oppStrength += strength(other)
+	}
+	if opponents > 0 {
+		f[1] = (float64(len(hand)) - float64(cards)/float64(opponents)) / HandSize
+		f[6] = float64(oppTrumps) / float64(opponents)
+	}
+	if cards > 0 {
+		f[7] = float64(oppStrength) / float64(cards) / 32
+	}
+	if board.Attacker == seat {
+		f[8] = 1
+	}
+	if board.Defender == seat {
+		f[9] = 1
+		f[10] = float64(len(board.TableCardList())) / HandSize
+	}
+	f[11] = float64(opponents)
+	return f
+}
+
+// Weights is a logistic evaluator: the chance of not being the durak is the
+// logistic function of a weighted sum of features of the position. Train
+// learns the weights from self-play.
+type Weights struct {
+	// Version is WeightsVersion.
+	Version int `json:"version"`
+	// Features names the features the weights go with.
+	Features []string  `json:"features"`
+	Bias     float64   `json:"bias"`
+	Weights  []float64 `json:"weights"`
+	// Games is the number of games the weights were trained on.
+	Games int `json:"games"`
+}
+
+// Evaluate returns the estimated chance that seat does not end up the durak.
+// A seat out of cards once the deck is gone has made it.
+func (w *Weights) Evaluate(board *Board, seat Player) float64 {
+	if len(board.Hands[seat]) == 0 && len(board.Deck) == 0 {
+		return 1
+	}
+	return sigmoid(w.score(features(board, seat)))
+}
+
+// score returns the weighted sum of the features f.
+func (w *Weights) score(f []float64) float64 {
+	x := w.Bias
+	for i, v := range f {
+		x += w.Weights[i] * v
+	}
+	return x
+}
+
+func sigmoid(x float64) float64 {
+	return 1 / (1 + math.Exp(-x))
+}
+
+// LoadWeights reads weights saved by Weights.Save. It refuses files of
+// another version or for other features.
+func LoadWeights(path string) (*Weights, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var w Weights
+	if err := json.Unmarshal(data, &w); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if w.Version != WeightsVersion {
+		return nil, fmt.Errorf("%s: weights of version %d, want %d", path, w.Version, WeightsVersion)
+	}
+	if !slices.Equal(w.Features, featureNames) || len(w.Weights) != len(featureNames) {
+		return nil, fmt.Errorf("%s: weights for other features", path)
+	}
+	return &w, nil
+}
+
+// Save writes the weights to path as JSON.
+func (w *Weights) Save(path string) error {
+	data, err := json.MarshalIndent(w, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
@@ -0,0 +1,76 @@
+package durak
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestWeightsRoundTrip(t *testing.T) {
+	w, err := Train(context.Background(), TrainConfig{Rules: DefaultRules(), Games: 20, Epochs: 2, Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "weights.json")
+	if err := w.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadWeights(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, w) {
+		t.Errorf("LoadWeights = %+v, want the weights saved, %+v", got, w)
+	}
+
+	// The weights own their feature list.
+	w.Features[0] = "renamed"
+	if featureNames[0] == "renamed" {
+		t.Error("changing the features of trained weights changed featureNames")
+	}
+}
+
+func TestLoadWeightsRefuses(t *testing.T) {
+	valid := func() *Weights {
+		return &Weights{
+			Version:  WeightsVersion,
+			Features: slices.Clone(featureNames),
+			Weights:  make([]float64, len(featureNames)),
+		}
+	}
+	tests := []struct {
+		name   string
+		change func(w *Weights)
+		err    string
+	}{
+		{name: "other version", change: func(w *Weights) { w.Version++ }, err: "version"},
+		{name: "other feature", change: func(w *Weights) { w.Features[0] = "renamed" }, err: "other features"},
+		{name: "missing feature", change: func(w *Weights) {
+			w.Features = w.Features[1:]
+			w.Weights = w.Weights[1:]
+		}, err: "other features"},
+		{name: "missing weight", change: func(w *Weights) { w.Weights = w.Weights[1:] }, err: "other features"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := valid()
+			tt.change(w)
+			data, err := json.Marshal(w)
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(t.TempDir(), "weights.json")
+			if err := os.WriteFile(path, data, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadWeights(path); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("LoadWeights = %v, want an error about %s", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package durak
+
+import "math/rand/v2"
+
+// Observer is implemented by AIs that learn from the moves played. The Engine
+// tells its observers about every game it deals and every move made through
+// Apply.
+type Observer interface {
+	// NewGame is called when board has been dealt, before its first move.
+	// Nothing seen in an earlier game holds for it.
+	NewGame(board *Board)
+	// Observe is called after move took the board from before to after.
+	Observe(before *Board, move Move, after *Board)
+}
+
+// Knowledge is what the table has seen during a game: which cards were
+// discarded, which cards a player is known to hold because they picked them
+// up, and which attacks a player took instead of beating. Every player can
+// know all of it; none of it reveals a hidden card directly.
+type Knowledge struct {
+	// Discarded holds the cards beaten and put aside so far.
+	Discarded []Card
+	// Known holds, per seat, the cards the seat picked up from the table or
+	// drew face up and has not played since.
+	Known [][]Card
+	// Unbeaten holds, per seat, the attack cards the seat took rather than
+	// beat since it last drew. The seat is assumed to hold no higher card
+	// of their suits. It may still hold trumps, kept back rather than spent
+	// on a cheap card.
+	Unbeaten [][]Card
+}
+
+// NewKnowledge returns an empty Knowledge for a game with the given number
+// of seats.
+func NewKnowledge(players int) *Knowledge {
+	return &Knowledge{
+		Known:    make([][]Card, players),
+		Unbeaten: make([][]Card, players),
+	}
+}
+
+// Observe records the public effects of move.
+func (k *Knowledge) Observe(before *Board, move Move, after *Board) {
+	seat := move.Seat
+	picked := 0
+
+	switch {
+	case move.Kind == MoveTake:
+		// Everything on the table is now known to be in the taker's hand,
+		// and they could not, or would not, beat what was left uncovered.
+		for _, tc := range before.Table {
+			if !tc.Covered {
+				k.Unbeaten[seat] = append(k.Unbeaten[seat], tc.Attack)
+			}
+		}
+		table := before.TableCardList()
+		picked = len(table)
+		k.Known[seat] = append(k.Known[seat], table...)
+	case move.Kind.HasCard():
+		k.Known[seat] = removeCard(k.Known[seat], move.Card)
+	}
+
+	// A round that ends without a take sends the table to the discards.
+	if after.Round > before.Round && move.Kind != MoveTake {
+		k.Discarded = append(k.Discarded, before.TableCardList()...)
+		if move.Kind.HasCard() {
+			k.Discarded = append(k.Discarded, move.Card)
+		}
+	}
+
+	// Fresh cards void what a seat could not beat before.
+	for p := range after.Hands {
+		drawn := len(after.Hands[p]) - len(before.Hands[p])
+		if Player(p) == seat {
+			drawn -= picked
+			if move.Kind.HasCard() {
+				drawn++
+			}
+		}
+		if drawn > 0 {
+			k.Unbeaten[p] = nil
+		}
+	}
+
+	// Whoever draws the face-up trump card is seen taking it.
+	if before.TrumpInDeck() && !after.TrumpInDeck() {
+		for p, hand := range after.Hands {
+			if containsCard(hand, after.TrumpCard) {
+				k.Known[p] = append(k.Known[p], after.TrumpCard)
+			}
+		}
+	}
+}
+
+// TrumpsOut returns how many trumps seat cannot account for: the trumps
+// that are neither in its hand, on the table nor discarded, so the others
+// or the deck hold them. It assumes k has seen the whole game.
+func (k *Knowledge) TrumpsOut(board *Board, seat Player) int {
+	// Every deck has all four suits of its ranks.
+	out := (board.CardsInPlay() + len(k.Discarded)) / 4
+	for _, seen := range [][]Card{k.Discarded, board.TableCardList(), board.Hand(seat)} {
+		for _, card := range seen {
+			if card.Suit == board.TrumpSuit {
+				out--
+			}
+		}
+	}
+	return out
+}
+
+// Determinize returns a copy of board in which every card seat cannot see is
+// dealt again at random: the other players' hands and the deck, except for
+// the face-up trump card at its bottom. Hand and deck sizes are kept. Known
+// cards stay with their holders and, where possible, players are not dealt
+// higher cards of the suit of attacks they took. A nil Knowledge deals
+// uniformly.
+func (k *Knowledge) Determinize(board *Board, seat Player, rng *rand.Rand) *Board {
+	det := board.Copy()
+
+	var hidden []Card
+	for p, hand := range det.Hands {
+		if Player(p) != seat {
+			hidden = append(hidden, hand...)
+		}
+	}
+	deckHidden := len(det.Deck)
+	if det.TrumpInDeck() {
+		deckHidden-- // The trump card is face up.
+	}
+	hidden = append(hidden, det.Deck[:deckHidden]...)
+
+	rng.Shuffle(len(hidden), func(i, j int) {
+		hidden[i], hidden[j] = hidden[j], hidden[i]
+	})
+
+	// Cards known to be in a hand go back there first.
+	known := make([][]Card, len(det.Hands))
+	if k != nil {
+		for p, hand := range det.Hands {
+			for _, card := range k.Known[p] {
+				if Player(p) != seat && len(known[p]) < len(hand) && containsCard(hidden, card) {
+					known[p] = append(known[p], card)
+					hidden = removeCard(hidden, card)
+				}
+			}
+		}
+	}
+
+	for p, hand := range det.Hands {
+		if Player(p) == seat {
+			continue
+		}
+		dealt := append(hand[:0], known[p]...)
+		if k != nil {
+			// Then cards that fit what the player failed to beat.
+			for i := 0; i < len(hidden) && len(dealt) < len(hand); {
+				if k.outranksUnbeaten(Player(p), hidden[i]) {
+					i++
+					continue
+				}
+				dealt = append(dealt, hidden[i])
+				hidden = append(hidden[:i], hidden[i+1:]...)
+			}
+		}
+		// Whatever is still missing is dealt blindly.
+		n := copy(hand[len(dealt):], hidden)
+		hidden = hidden[n:]
+	}
+	copy(det.Deck[:deckHidden], hidden)
+	return det
+}
+
+// outranksUnbeaten reports whether card is a higher card of the suit of an
+// attack seat took. Trumps of another suit are not ruled out, as taking
+// says nothing about the trumps a player would rather keep.
+func (k *Knowledge) outranksUnbeaten(seat Player, card Card) bool {
+	for _, attack := range k.Unbeaten[seat] {
+		if card.Suit == attack.Suit && card.Rank > attack.Rank {
+			return true
+		}
+	}
+	return false
+}
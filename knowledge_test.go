@@ -0,0 +1,153 @@
+package durak
+
+import (
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestNewGameForgetsKnowledge(t *testing.T) {
+	engine := NewEngine(DefaultRules())
+	m := engine.AI.(*mcts)
+	rng := rand.New(rand.NewPCG(1, 2))
+	board := engine.dealWith(rng)
+	for over, _ := engine.CheckGameOver(board); !over; over, _ = engine.CheckGameOver(board) {
+		moves := engine.GetLegalMoves(board)
+		if err := engine.Apply(board, moves[rng.IntN(len(moves))]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(m.knowledge.Discarded) == 0 {
+		t.Fatal("nothing was discarded in a whole game")
+	}
+
+	engine.dealWith(rng)
+	if m.knowledge != nil || m.searches != nil {
+		t.Errorf("the AI still remembers the last game after a new deal")
+	}
+}
+
+func TestTrumpsOut(t *testing.T) {
+	// A 24-card deck with hearts trump: 9♥ is discarded, 10♥ is on the
+	// table and seat 0 holds J♥, which leaves Q♥, K♥ and A♥ out.
+	b := testBoard(t, "J♥ 9♣", "10♣ A♥", "Q♦")
+	b.TrumpSuit, b.TrumpCard = Hearts, cards(t, "K♥")[0]
+	b.Deck = append(cards(t, "Q♥ 9♠"), b.TrumpCard)
+	b.Table = []TableCards{{Attack: cards(t, "10♥")[0]}}
+	k := NewKnowledge(3)
+	k.Discarded = cards(t, "9♥ 9♦")
+	// Fill the rest of the deck up as discards of the other suits.
+	for _, card := range Deck24.Cards() {
+		if card.Suit != Hearts && !containsCard(k.Discarded, card) &&
+			!containsCard(b.Deck, card) && !slices.ContainsFunc(b.Hands, func(h []Card) bool { return containsCard(h, card) }) {
+			k.Discarded = append(k.Discarded, card)
+		}
+	}
+	if got := k.TrumpsOut(b, 0); got != 3 {
+		t.Errorf("TrumpsOut = %d, want 3", got)
+	}
+}
+
+// cardCount counts every card on the board, wherever it is.
+func cardCount(b *Board) map[Card]int {
+	count := make(map[Card]int)
+	for _, hand := range b.Hands {
+		for _, card := range hand {
+			count[card]++
+		}
+	}
+	for _, card := range append(b.TableCardList(), b.Deck...) {
+		count[card]++
+	}
+	return count
+}
+
+// checkDeterminized reports how det differs from board in what seat can see.
+func checkDeterminized(t *testing.T, board, det *Board, k *Knowledge, seat Player) {
+	t.Helper()
+	if !slices.Equal(det.Hands[seat], board.Hands[seat]) {
+		t.Fatalf("seat %d holds %v, want its own hand %v", seat, det.Hands[seat], board.Hands[seat])
+	}
+	if !slices.Equal(det.Table, board.Table) {
+		t.Fatalf("table %v, want %v", det.Table, board.Table)
+	}
+	if len(det.Deck) != len(board.Deck) {
+		t.Fatalf("%d cards in the deck, want %d", len(det.Deck), len(board.Deck))
+	}
+	if board.TrumpInDeck() && det.Deck[len(det.Deck)-1] != board.TrumpCard {
+		t.Fatalf("deck ends with %v, want the trump card %v", det.Deck[len(det.Deck)-1], board.TrumpCard)
+	}
+	for p, hand := range det.Hands {
+		if len(hand) != len(board.Hands[p]) {
+			t.Fatalf("seat %d holds %d cards, want %d", p, len(hand), len(board.Hands[p]))
+		}
+		for _, card := range k.Known[p] {
+			if !containsCard(hand, card) {
+				t.Fatalf("seat %d is known to hold %v but was dealt %v", p, card, hand)
+			}
+		}
+	}
+	if !maps.Equal(cardCount(det), cardCount(board)) {
+		t.Fatalf("determinized cards %v, want %v", cardCount(det), cardCount(board))
+	}
+}
+
+func TestDeterminizeKeepsWhatIsSeen(t *testing.T) {
+	rules := Rules{Deck: Deck36, Players: 3, Transfer: true, AttackLimit: 6}
+	for seed := range uint64(20) {
+		e := &Engine{Rules: rules}
+		rng := rand.New(rand.NewPCG(seed, 1))
+		board := e.dealWith(rng)
+		k := NewKnowledge(rules.Players)
+		for over, _ := e.CheckGameOver(board); !over; over, _ = e.CheckGameOver(board) {
+			seat := board.ToMove()
+			det := k.Determinize(board, seat, rng)
+			checkDeterminized(t, board, det, k, seat)
+
+			moves := e.GetLegalMoves(board)
+			move := moves[rng.IntN(len(moves))]
+			before := board.Copy()
+			if err := e.Apply(board, move); err != nil {
+				t.Fatal(err)
+			}
+			k.Observe(before, move, board)
+		}
+	}
+}
+
+func TestDeterminizeFollowsKnowledge(t *testing.T) {
+	// Seat 0 attacks seat 1 with spades trump. Seat 1 took 9♥ and is
+	// known to hold it, seat 2 picked up K♦.
+	b := testBoard(t, "6♥ 7♥", "9♥ 10♥ J♥ Q♠ K♠", "K♦ 10♣ J♣ Q♣ K♣")
+	b.Deck = nil
+	for _, card := range Deck36.Cards() {
+		if card != b.TrumpCard && !slices.ContainsFunc(b.Hands, func(h []Card) bool { return containsCard(h, card) }) {
+			b.Deck = append(b.Deck, card)
+		}
+	}
+	b.Deck = append(b.Deck, b.TrumpCard)
+	k := NewKnowledge(3)
+	k.Known[1] = cards(t, "9♥")
+	k.Known[2] = cards(t, "K♦")
+	k.Unbeaten[1] = cards(t, "9♥")
+
+	rng := rand.New(rand.NewPCG(1, 2))
+	trumps := 0
+	for range 200 {
+		det := k.Determinize(b, 0, rng)
+		checkDeterminized(t, b, det, k, 0)
+		for _, card := range det.Hands[1] {
+			if card.Suit == Hearts && card.Rank > Nine {
+				t.Fatalf("seat 1 was dealt %v, a higher heart than the 9♥ it took", card)
+			}
+			if card.Suit == b.TrumpSuit {
+				trumps++
+			}
+		}
+	}
+	// Taking a heart says nothing about trumps, which seat 1 may have kept.
+	if trumps == 0 {
+		t.Error("seat 1 was never dealt a trump after taking a heart")
+	}
+}
@@ -0,0 +1,190 @@
+package durak
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Reasons a move can be rejected. Apply and Validate wrap them in a
+// MoveError.
+var (
+	ErrGameOver         = errors.New("the game is over")
+	ErrNotYourTurn      = errors.New("it is not your turn")
+	ErrMustAttack       = errors.New("you have to open the attack with a card")
+	ErrAttackOpen       = errors.New("the attack is already open, throw in instead")
+	ErrDefending        = errors.New("you are defending: beat, take or transfer")
+	ErrNotDefending     = errors.New("only the defender can do that")
+	ErrNotInHand        = errors.New("you do not hold that card")
+	ErrRankNotOnTable   = errors.New("only ranks already on the table can be thrown in")
+	ErrAttackLimit      = errors.New("the attack limit for this round is reached")
+	ErrDefenderHand     = errors.New("the defender has no cards left to cover another attack")
+	ErrNoSuchAttack     = errors.New("there is no uncovered attack in that slot")
+	ErrCannotBeat       = errors.New("that card does not beat the attack")
+	ErrTransferDisabled = errors.New("transfers are not allowed in this game")
+	ErrTransferCovered  = errors.New("cannot transfer after covering a card")
+	ErrTransferRank     = errors.New("a transfer needs the rank of the attack cards")
+	ErrTransferHand     = errors.New("the next player holds too few cards to take the transfer")
+)
+
+// MoveError explains why the engine rejected a move.
+type MoveError struct {
+	Move   Move
+	Reason error
+}
+
+func (e *MoveError) Error() string {
+	return fmt.Sprintf("illegal move %q: %v", e.Move, e.Reason)
+}
+
+func (e *MoveError) Unwrap() error {
+	return e.Reason
+}
+
+// Validate checks whether move.Seat may make the move on the board. It
+// returns nil for a legal move and a *MoveError otherwise.
+func (e *Engine) Validate(board *Board, move Move) error {
+	if reason := e.validate(board, move); reason != nil {
+		return &MoveError{Move: move, Reason: reason}
+	}
+	return nil
+}
+
+// Apply validates move and plays it, then tells the observers. The board is
+// left untouched if the move is illegal.
+func (e *Engine) Apply(board *Board, move Move) error {
+	if err := e.Validate(board, move); err != nil {
+		return err
+	}
+	observers := e.observers()
+	if len(observers) == 0 {
+		e.PlayMove(board, move)
+		return nil
+	}
+	before := board.Copy()
+	e.PlayMove(board, move)
+	for _, o := range observers {
+		o.Observe(before, move, board)
+	}
+	return nil
+}
+
+func (e *Engine) validate(board *Board, move Move) error {
+	if over, _ := e.CheckGameOver(board); over {
+		return ErrGameOver
+	}
+	if move.Seat != board.ToMove() {
+		return ErrNotYourTurn
+	}
+	defending := board.Uncovered() > 0
+
+	switch move.Kind {
+	case MoveAttack, MoveThrowIn, MovePass:
+		if defending {
+			return ErrDefending
+		}
+		if len(board.Table) == 0 && move.Kind != MoveAttack {
+			return ErrMustAttack
+		}
+		if len(board.Table) > 0 && move.Kind == MoveAttack {
+			return ErrAttackOpen
+		}
+	case MoveDefend, MoveTake, MoveTransfer:
+		if !defending {
+			return ErrNotDefending
+		}
+	default:
+		return fmt.Errorf("unknown move kind %d", move.Kind)
+	}
+
+	if !move.Kind.HasCard() {
+		return nil
+	}
+	card := move.Card
+	if !containsCard(board.Hands[move.Seat], card) {
+		return ErrNotInHand
+	}
+
+	switch move.Kind {
+	case MoveTransfer:
+		if err := e.transferError(board); err != nil {
+			return err
+		}
+		if card.Rank != board.Table[0].Attack.Rank {
+			return ErrTransferRank
+		}
+	case MoveDefend:
+		if move.Target < 0 || move.Target >= len(board.Table) || board.Table[move.Target].Covered {
+			return ErrNoSuchAttack
+		}
+		if !e.CanBeat(board.Table[move.Target].Attack, card, board.TrumpSuit) {
+			return ErrCannotBeat
+		}
+	case MoveThrowIn:
+		if !board.TableRanks()[card.Rank] {
+			return ErrRankNotOnTable
+		}
+		return e.throwInError(board)
+	}
+	return nil
+}
+
+// throwInError reports why one more attack card does not fit, if it does
+// not: the round must be under its attack limit and the undefended attack
+// cards may never outnumber the defender's hand.
+func (e *Engine) throwInError(board *Board) error {
+	if len(board.Table) >= e.AttackLimit(board) {
+		return ErrAttackLimit
+	}
+	if board.Uncovered() >= len(board.Hands[board.Defender]) {
+		return ErrDefenderHand
+	}
+	return nil
+}
+
+func (e *Engine) canThrowIn(board *Board) bool {
+	return e.throwInError(board) == nil
+}
+
+// transferError reports why the defender may not pass the attack on, if they
+// may not: the variant must be enabled, nothing may be covered yet, every
+// attack card must share a rank, the next defender must hold enough cards to
+// face them all and the round must be under its attack limit.
+func (e *Engine) transferError(board *Board) error {
+	if !e.Rules.Transfer {
+		return ErrTransferDisabled
+	}
+	if len(board.Table) == 0 {
+		return ErrNoSuchAttack
+	}
+	rank := board.Table[0].Attack.Rank
+	for _, tc := range board.Table {
+		if tc.Covered {
+			return ErrTransferCovered
+		}
+		if tc.Attack.Rank != rank {
+			return ErrTransferRank
+		}
+	}
+	next := board.Next(board.Defender)
+	if next == board.Defender || len(board.Hands[next]) < len(board.Table)+1 {
+		return ErrTransferHand
+	}
+	if len(board.Table) >= e.AttackLimit(board) {
+		return ErrAttackLimit
+	}
+	return nil
+}
+
+func (e *Engine) canTransfer(board *Board) bool {
+	return e.transferError(board) == nil
+}
+
+// containsCard reports whether hand holds card.
+func containsCard(hand []Card, card Card) bool {
+	for _, c := range hand {
+		if c == card {
+			return true
+		}
+	}
+	return false
+}
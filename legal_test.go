@@ -0,0 +1,223 @@
+package durak
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// cards parses space-separated cards, e.g. "6♠ 10h".
+func cards(t *testing.T, s string) []Card {
+	t.Helper()
+	var cs []Card
+	for _, f := range strings.Fields(s) {
+		c, err := ParseCard(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cs = append(cs, c)
+	}
+	return cs
+}
+
+// testBoard deals the given hands, with spades trump and a few cards left in
+// the deck, and lets seat 0 attack seat 1 in the second round.
+func testBoard(t *testing.T, hands ...string) *Board {
+	t.Helper()
+	b := &Board{Deck: cards(t, "6♦ 7♦ 8♠"), Round: 1}
+	b.TrumpCard = b.Deck[len(b.Deck)-1]
+	b.TrumpSuit = b.TrumpCard.Suit
+	for _, h := range hands {
+		b.Hands = append(b.Hands, cards(t, h))
+	}
+	b.SetAttacker(0)
+	return b
+}
+
+func TestValidate(t *testing.T) {
+	rules := Rules{Deck: Deck36, Players: 3, AttackLimit: 6}
+	transfer := rules
+	transfer.Transfer = true
+	limited := transfer
+	limited.AttackLimit = 1
+
+	attack := func(b *Board, card Card) *Board {
+		b.Table = append(b.Table, TableCards{Attack: card})
+		b.Hands[0] = removeCard(b.Hands[0], card)
+		return b
+	}
+	tests := []struct {
+		name  string
+		rules Rules
+		board func(t *testing.T) *Board
+		move  func(t *testing.T) Move
+		want  error
+	}{
+		{
+			name:  "legal attack",
+			rules: rules,
+			board: func(t *testing.T) *Board { return testBoard(t, "9♥ 10♣", "7♥ J♣", "Q♦") },
+			move:  func(t *testing.T) Move { return Move{Kind: MoveAttack, Seat: 0, Card: cards(t, "9♥")[0]} },
+		},
+		{
+			name:  "wrong turn",
+			rules: rules,
+			board: func(t *testing.T) *Board { return testBoard(t, "9♥ 10♣", "7♥ J♣", "Q♦") },
+			move:  func(t *testing.T) Move { return Move{Kind: MoveAttack, Seat: 1, Card: cards(t, "7♥")[0]} },
+			want:  ErrNotYourTurn,
+		},
+		{
+			name:  "card not in hand",
+			rules: rules,
+			board: func(t *testing.T) *Board { return testBoard(t, "9♥ 10♣", "7♥ J♣", "Q♦") },
+			move:  func(t *testing.T) Move { return Move{Kind: MoveAttack, Seat: 0, Card: cards(t, "A♥")[0]} },
+			want:  ErrNotInHand,
+		},
+		{
+			name:  "cannot beat a higher card",
+			rules: rules,
+			board: func(t *testing.T) *Board {
+				return attack(testBoard(t, "9♥ 10♣", "7♥ J♣", "Q♦"), cards(t, "9♥")[0])
+			},
+			move: func(t *testing.T) Move {
+				return Move{Kind: MoveDefend, Seat: 1, Card: cards(t, "7♥")[0], Target: 0}
+			},
+			want: ErrCannotBeat,
+		},
+		{
+			name:  "cannot beat with another plain suit",
+			rules: rules,
+			board: func(t *testing.T) *Board {
+				return attack(testBoard(t, "9♥ 10♣", "7♥ J♣", "Q♦"), cards(t, "9♥")[0])
+			},
+			move: func(t *testing.T) Move {
+				return Move{Kind: MoveDefend, Seat: 1, Card: cards(t, "J♣")[0], Target: 0}
+			},
+			want: ErrCannotBeat,
+		},
+		{
+			name:  "beat with a trump",
+			rules: rules,
+			board: func(t *testing.T) *Board {
+				return attack(testBoard(t, "9♥ 10♣", "6♠ J♣", "Q♦"), cards(t, "9♥")[0])
+			},
+			move: func(t *testing.T) Move {
+				return Move{Kind: MoveDefend, Seat: 1, Card: cards(t, "6♠")[0], Target: 0}
+			},
+		},
+		{
+			name:  "no such attack",
+			rules: rules,
+			board: func(t *testing.T) *Board {
+				return attack(testBoard(t, "9♥ 10♣", "10♥ J♣", "Q♦"), cards(t, "9♥")[0])
+			},
+			move: func(t *testing.T) Move {
+				return Move{Kind: MoveDefend, Seat: 1, Card: cards(t, "10♥")[0], Target: 1}
+			},
+			want: ErrNoSuchAttack,
+		},
+		{
+			name:  "transfer disabled",
+			rules: rules,
+			board: func(t *testing.T) *Board {
+				return attack(testBoard(t, "9♥ 10♣", "9♣ J♣", "Q♦ K♦"), cards(t, "9♥")[0])
+			},
+			move: func(t *testing.T) Move {
+				return Move{Kind: MoveTransfer, Seat: 1, Card: cards(t, "9♣")[0]}
+			},
+			want: ErrTransferDisabled,
+		},
+		{
+			name:  "transfer",
+			rules: transfer,
+			board: func(t *testing.T) *Board {
+				return attack(testBoard(t, "9♥ 10♣", "9♣ J♣", "Q♦ K♦"), cards(t, "9♥")[0])
+			},
+			move: func(t *testing.T) Move {
+				return Move{Kind: MoveTransfer, Seat: 1, Card: cards(t, "9♣")[0]}
+			},
+		},
+		{
+			name:  "transfer with another rank",
+			rules: transfer,
+			board: func(t *testing.T) *Board {
+				return attack(testBoard(t, "9♥ 10♣", "9♣ J♣", "Q♦ K♦"), cards(t, "9♥")[0])
+			},
+			move: func(t *testing.T) Move {
+				return Move{Kind: MoveTransfer, Seat: 1, Card: cards(t, "J♣")[0]}
+			},
+			want: ErrTransferRank,
+		},
+		{
+			name:  "transfer to a short hand",
+			rules: transfer,
+			board: func(t *testing.T) *Board {
+				return attack(testBoard(t, "9♥ 10♣", "9♣ J♣", "Q♦"), cards(t, "9♥")[0])
+			},
+			move: func(t *testing.T) Move {
+				return Move{Kind: MoveTransfer, Seat: 1, Card: cards(t, "9♣")[0]}
+			},
+			want: ErrTransferHand,
+		},
+		{
+			name:  "transfer over the attack limit",
+			rules: limited,
+			board: func(t *testing.T) *Board {
+				return attack(testBoard(t, "9♥ 10♣", "9♣ J♣", "Q♦ K♦"), cards(t, "9♥")[0])
+			},
+			move: func(t *testing.T) Move {
+				return Move{Kind: MoveTransfer, Seat: 1, Card: cards(t, "9♣")[0]}
+			},
+			want: ErrAttackLimit,
+		},
+		{
+			name:  "throw in over the attack limit",
+			rules: limited,
+			board: func(t *testing.T) *Board {
+				b := attack(testBoard(t, "9♥ 9♦", "10♥ J♣", "Q♦"), cards(t, "9♥")[0])
+				b.Table[0].Defense, b.Table[0].Covered = cards(t, "10♥")[0], true
+				b.Hands[1] = cards(t, "J♣")
+				return b
+			},
+			move: func(t *testing.T) Move {
+				return Move{Kind: MoveThrowIn, Seat: 0, Card: cards(t, "9♦")[0]}
+			},
+			want: ErrAttackLimit,
+		},
+		{
+			name:  "throw in a rank not on the table",
+			rules: rules,
+			board: func(t *testing.T) *Board {
+				b := attack(testBoard(t, "9♥ 8♦", "10♥ J♣", "Q♦"), cards(t, "9♥")[0])
+				b.Table[0].Defense, b.Table[0].Covered = cards(t, "10♥")[0], true
+				b.Hands[1] = cards(t, "J♣")
+				return b
+			},
+			move: func(t *testing.T) Move {
+				return Move{Kind: MoveThrowIn, Seat: 0, Card: cards(t, "8♦")[0]}
+			},
+			want: ErrRankNotOnTable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Engine{Rules: tt.rules}
+			move := tt.move(t)
+			err := e.Validate(tt.board(t), move)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("Validate(%v) = %v, want nil", move, err)
+				}
+				return
+			}
+			var moveErr *MoveError
+			if !errors.As(err, &moveErr) {
+				t.Fatalf("Validate(%v) = %v, want a *MoveError", move, err)
+			}
+			if moveErr.Move != move || !errors.Is(err, tt.want) {
+				t.Errorf("Validate(%v) = %v, want %v for %v", move, err, tt.want, move)
+			}
+		})
+	}
+}
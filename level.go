@@ -0,0 +1,63 @@
+package durak
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Level is the playing strength of the AI.
+type Level int
+
+const (
+	// Easy searches briefly, forgets which cards have been played, never
+	// solves the endgame and often blunders.
+	Easy Level = iota
+	// Normal tracks the cards and searches a fixed number of playouts,
+	// with the odd slip.
+	Normal
+	// Hard searches for most of a second on every core, with playouts
+	// that play sensibly.
+	Hard
+	// Expert searches like Hard, for two seconds.
+	Expert
+)
+
+var levelNames = [...]string{
+	Easy:   "easy",
+	Normal: "normal",
+	Hard:   "hard",
+	Expert: "expert",
+}
+
+// String returns the name of the level, e.g. "hard".
+func (l Level) String() string {
+	if l < 0 || int(l) >= len(levelNames) {
+		return fmt.Sprintf("Level(%d)", int(l))
+	}
+	return levelNames[l]
+}
+
+// ParseLevel parses a level name as returned by Level.String, ignoring case.
+func ParseLevel(s string) (Level, error) {
+	for l := Easy; l <= Expert; l++ {
+		if strings.EqualFold(s, l.String()) {
+			return l, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown level %q", s)
+}
+
+// Config returns the search configuration for the level.
+func (l Level) Config() MCTSConfig {
+	switch l {
+	case Easy:
+		return MCTSConfig{Budget: Budget{Iterations: 200}, Workers: 1, NoCardTracking: true, MistakeRate: 0.2, EndgameCards: -1}
+	case Normal:
+		return MCTSConfig{Budget: Budget{Iterations: 2000}, MistakeRate: 0.05}
+	case Hard:
+		return MCTSConfig{Budget: Budget{Time: 800 * time.Millisecond}, Rollout: HeuristicRollout{}}
+	default:
+		return MCTSConfig{Budget: Budget{Time: 2 * time.Second}, Rollout: HeuristicRollout{}}
+	}
+}
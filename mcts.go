@@ -1,17 +1,116 @@
-package main
+package durak
 
 import (
+	"cmp"
+	"context"
 	"log"
 	"math"
 	"math/rand/v2"
+	"runtime"
+	"slices"
+	"sync"
+	"time"
 )
 
+// AI chooses a move for the player to move on a board, whichever seat that
+// is, so one AI can play any seat or several at once. Solve must return
+// promptly once ctx is done, with the best move found so far.
 type AI interface {
-	Solve(board *Board) Move
+	Solve(ctx context.Context, board *Board) Decision
 }
 
+// Decision is the move an AI settled on and what it took to find it. It
+// encodes to JSON as a report on the search.
+type Decision struct {
+	Move Move `json:"move"`
+	// Playouts is the number of simulated games the search completed.
+	Playouts int `json:"playouts"`
+	// Elapsed is the time spent searching.
+	Elapsed time.Duration `json:"elapsed_ns"`
+	// Reused is the number of playouts carried over from earlier searches
+	// through the moves played since.
+	Reused int `json:"reused"`
+	// Endgame is the exact value of the position if the endgame solver
+	// chose the move, nil otherwise.
+	Endgame *EndgameResult `json:"endgame,omitempty"`
+	// Moves are the moves the search tried, the most visited first, if it
+	// ran. Move is the first of them unless the AI slipped on purpose.
+	Moves []MoveStats `json:"moves,omitempty"`
+	// Principal is the line of play the search expects, every player
+	// making their most visited move, starting with the first of Moves.
+	// It ends where the playouts thin out.
+	Principal []Move `json:"principal,omitempty"`
+}
+
+// MoveStats is what the search found out about a move of the seat to move.
+type MoveStats struct {
+	Move Move `json:"move"`
+	// Visits is the number of playouts through the move.
+	Visits int `json:"visits"`
+	// WinRate is the share of those playouts the seat did not lose, a draw
+	// counting half.
+	WinRate float64 `json:"win_rate"`
+	// Low and High bound WinRate with 95% confidence.
+	Low  float64 `json:"low"`
+	High float64 `json:"high"`
+}
+
+// Budget limits a search. The search stops at whichever limit it reaches
+// first; zero means no limit of that kind.
+type Budget struct {
+	// Iterations caps the number of playouts.
+	Iterations int
+	// Time caps the wall-clock time spent.
+	Time time.Duration
+}
+
+// MCTSConfig configures the Monte Carlo tree search AI.
+type MCTSConfig struct {
+	// Budget limits each decision. If it sets no limit at all, the search
+	// runs 100 iterations.
+	Budget Budget
+	// Workers is the number of goroutines searching in parallel, each on a
+	// tree of its own. Zero means one per CPU.
+	Workers int
+	// Seed makes the search reproducible: with the same seed, the same
+	// iteration budget and the same sequence of boards, the AI chooses the
+	// same moves whatever the scheduling of its workers. Zero seeds from
+	// the system. A time budget or cancellation still stops the workers at
+	// unpredictable points.
+	Seed uint64
+	// NoCardTracking deals the hidden cards at random, ignoring which cards
+	// have been discarded or picked up.
+	NoCardTracking bool
+	// MistakeRate is the chance of deliberately playing a random move other
+	// than the best one.
+	MistakeRate float64
+	// Rollout plays out the simulated games. Nil means RandomRollout.
+	Rollout RolloutPolicy
+	// EndgameCards hands perfect-information endgames with at most this
+	// many cards in the hands and on the table to SolveEndgame. Zero means
+	// 12, a negative number never solves. Only a search that tracked the
+	// cards since the deal can tell who holds what once the deck is gone,
+	// so with NoCardTracking, or for an AI that joined a game under way,
+	// the endgame is never solved either.
+	EndgameCards int
+	// TableBytes bounds the memory of the transposition tables, which let
+	// the search share what it learned between positions reached by
+	// different orders of moves, and between decisions. It is split
+	// between the workers. Zero means 16 MiB, a negative number does
+	// without.
+	TableBytes int
+	// Evaluator, if set, cuts the simulated games short: after
+	// RolloutDepth moves outside the tree, a game still going is scored by
+	// the evaluator instead of being played to the end.
+	Evaluator Evaluator
+	// RolloutDepth is the number of moves simulated before the Evaluator
+	// takes over. Zero means 10.
+	RolloutDepth int
+}
+
+// GameEngine is the part of the Engine the search needs.
 type GameEngine interface {
-	// Returns gameover (bool) & a value if there's a winner
+	// Returns gameover (bool) & the durak, -1 for a draw
 	CheckGameOver(board *Board) (bool, Player)
 	// Get all available moves
 	GetLegalMoves(board *Board) []Move
@@ -19,74 +118,394 @@ type GameEngine interface {
 	PlayMove(board *Board, move Move)
 	// Check if a move is valid
 	CanBeat(attack Card, defense Card, trump Suit) bool
-	// GetOpponent returns the other player
-	GetOpponent(player Player) Player
 }
 
 type mcts struct {
 	engine              GameEngine
-	depth               int
+	budget              Budget
+	workers             int
 	simulationStepLimit int
+	tracking            bool
+	mistakeRate         float64
+	rollout             RolloutPolicy
+	endgameCards        int
+	tableBytes          int
+	evaluator           Evaluator
+	knowledge           *Knowledge
+	dealt               int        // cards dealt in the game, zero if the deal was missed
+	rng                 *rand.Rand // seeds the workers' generators
+
+	// The workers' trees are kept between decisions, one set per seat
+	// searched for, and follow the moves played. An AI playing several
+	// seats thus picks up each seat's trees on its next turn.
+	searches map[Player]*searchState
+
+	// The transposition tables are made on first use and kept for the
+	// game: one per worker, so the workers need no locks and stay
+	// reproducible. The endgame solver shares the first one, as it is done
+	// before the workers start.
+	tables []*TranspositionTable
+}
+
+// searchState holds the workers' trees of the last search for a seat, as
+// long as they stand for the board in at.
+type searchState struct {
+	roots []*Node
+	at    *Board
 }
 
-// Node represents a node in the Monte Carlo search tree
+// Node represents a node in the Monte Carlo search tree. The tree is built
+// over information sets: a node stands for the moves played so far, whatever
+// the hidden cards turn out to be, so its children are only the moves that
+// were legal in some sampled deal. The statistics of a node belong to the
+// seat that played its move, so every player in the tree plays for
+// themselves.
 type Node struct {
-	move         Move
-	parent       *Node
-	children     []*Node
-	wins         float64
-	visits       int
-	untriedMoves []Move
-	playerToMove Player
+	move     Move
+	parent   *Node
+	children []*Node
+	wins     float64 // for move.Seat
+	visits   int
+	avail    int    // times the move was legal when its parent was selected
+	key      uint64 // position after the move, in the transposition table
 }
 
-func NewMCTS(engine GameEngine, depth int) AI {
+// NewMCTS returns an AI that runs a Monte Carlo tree search within the
+// configured budget per decision.
+func NewMCTS(engine GameEngine, cfg MCTSConfig) AI {
+	if cfg.Budget == (Budget{}) {
+		cfg.Budget.Iterations = 100
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = runtime.NumCPU()
+	}
+	if cfg.Rollout == nil {
+		cfg.Rollout = RandomRollout{}
+	}
+	if cfg.EndgameCards == 0 {
+		cfg.EndgameCards = 12
+	}
+	if cfg.TableBytes == 0 {
+		cfg.TableBytes = 16 << 20
+	}
+	stepLimit := 100 // Limit simulations to 100 moves
+	if cfg.Evaluator != nil {
+		stepLimit = cfg.RolloutDepth
+		if stepLimit <= 0 {
+			stepLimit = 10
+		}
+	}
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
 	return &mcts{
 		engine:              engine,
-		depth:               depth,
-		simulationStepLimit: 100, // Limit simulations to 100 moves
+		budget:              cfg.Budget,
+		workers:             cfg.Workers,
+		simulationStepLimit: stepLimit,
+		tracking:            !cfg.NoCardTracking,
+		mistakeRate:         cfg.MistakeRate,
+		rollout:             cfg.Rollout,
+		endgameCards:        cfg.EndgameCards,
+		tableBytes:          cfg.TableBytes,
+		evaluator:           cfg.Evaluator,
+		rng:                 rand.New(rand.NewPCG(seed, seed)),
+	}
+}
+
+// NewGame forgets the cards seen, the search trees and the transposition
+// tables of the last game.
+func (m *mcts) NewGame(board *Board) {
+	m.knowledge = nil
+	m.dealt = board.CardsInPlay()
+	for _, tt := range m.tables {
+		tt.Clear()
+	}
+	m.searches = nil
+}
+
+// Observe keeps track of the cards seen during the game, so the hidden cards
+// can be dealt in line with them. It also moves the search trees down to the
+// subtree of the move played, so the next search can build on them.
+func (m *mcts) Observe(before *Board, move Move, after *Board) {
+	if m.tracking {
+		if m.knowledge == nil {
+			m.knowledge = NewKnowledge(before.Players())
+		}
+		m.knowledge.Observe(before, move, after)
+	}
+
+	for seat, state := range m.searches {
+		if !sameView(state.at, before, seat) {
+			delete(m.searches, seat)
+			continue
+		}
+		for i, root := range state.roots {
+			child := root.child(move)
+			if child == nil {
+				child = &Node{}
+			}
+			child.parent = nil
+			state.roots[i] = child
+		}
+		state.at = after.Copy()
 	}
 }
 
-func (m *mcts) Solve(board *Board) Move {
+// Solve searches the board until the budget runs out or ctx is done and
+// returns the best move found.
+func (m *mcts) Solve(ctx context.Context, board *Board) Decision {
+	start := time.Now()
+	if m.budget.Time > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.budget.Time)
+		defer cancel()
+	}
+
 	legalMoves := m.engine.GetLegalMoves(board)
 	if len(legalMoves) == 0 {
-		return Move{take: true}
+		return Decision{Move: Move{Kind: MoveTake, Seat: board.ToMove()}}
+	}
+	if m.tables == nil && m.tableBytes > 0 {
+		m.tables = make([]*TranspositionTable, m.workers)
+		for i := range m.tables {
+			m.tables[i] = NewTranspositionTable(m.tableBytes / len(m.tables))
+		}
+	}
+	if result, ok := m.solveEndgame(ctx, board); ok {
+		return Decision{Move: result.Move, Elapsed: time.Since(start), Endgame: &result}
 	}
 	if len(legalMoves) == 1 {
-		return legalMoves[0]
+		return Decision{Move: legalMoves[0]}
 	}
 
-	root := &Node{
-		untriedMoves: m.engine.GetLegalMoves(board),
-		playerToMove: board.Attacker,
+	// The search plays for the seat to move at the root, and may only use
+	// what that seat can see.
+	seat := board.ToMove()
+
+	// Root parallelisation: every worker grows a tree of its own with its
+	// own random numbers, and the root statistics are summed at the end.
+	// The seeds are drawn up front so they do not depend on scheduling.
+	// The trees of the seat's last search are picked up again if they
+	// followed the game to this board.
+	decision := Decision{}
+	state := m.searches[seat]
+	if state == nil || !sameView(state.at, board, seat) {
+		state = &searchState{roots: make([]*Node, m.workers)}
+		for i := range state.roots {
+			state.roots[i] = &Node{}
+		}
+	}
+	for _, root := range state.roots {
+		for _, child := range root.children {
+			decision.Reused += child.visits
+		}
+	}
+	state.at = board.Copy()
+	if m.searches == nil {
+		m.searches = make(map[Player]*searchState)
 	}
+	m.searches[seat] = state
+	roots := state.roots
 
-	for i := 0; i < m.depth; i++ {
-		node := root
-		simulationBoard := board.Copy()
+	playouts := make([]int, m.workers)
+	var wg sync.WaitGroup
+	for i := range roots {
+		iterations := 0
+		if m.budget.Iterations > 0 {
+			// Share the iterations out, the first workers taking the
+			// remainder.
+			iterations = m.budget.Iterations / m.workers
+			if i < m.budget.Iterations%m.workers {
+				iterations++
+			}
+			if iterations == 0 {
+				continue
+			}
+		}
+		rng := rand.New(rand.NewPCG(m.rng.Uint64(), m.rng.Uint64()))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			playouts[i] = m.search(ctx, board, seat, roots[i], m.table(i), iterations, rng)
+		}()
+	}
+	wg.Wait()
 
-		// 1. Selection
-		for len(node.untriedMoves) == 0 && len(node.children) > 0 {
-			node = node.selectChild()
-			m.engine.PlayMove(simulationBoard, node.move)
+	decision.Elapsed = time.Since(start)
+	for _, n := range playouts {
+		decision.Playouts += n
+	}
+
+	// Return the most visited move, the most robust choice when the
+	// statistics are spread over many deals. The moves are merged in
+	// the order they are generated, so ties break the same way every time.
+	bestScore := -1.0
+	mostVisits := 0
+	for _, move := range legalMoves {
+		var wins float64
+		visits := 0
+		for _, root := range roots {
+			if child := root.child(move); child != nil {
+				wins += child.wins
+				visits += child.visits
+			}
+		}
+		if visits > 0 {
+			score := wins / float64(visits)
+			lo, hi := wilson(score, visits)
+			decision.Moves = append(decision.Moves, MoveStats{Move: move, Visits: visits, WinRate: score, Low: lo, High: hi})
+			if visits > mostVisits || visits == mostVisits && score > bestScore {
+				mostVisits = visits
+				bestScore = score
+				decision.Move = move
+			}
+		}
+	}
+
+	slices.SortStableFunc(decision.Moves, func(a, b MoveStats) int {
+		if a.Visits != b.Visits {
+			return b.Visits - a.Visits
+		}
+		return cmp.Compare(b.WinRate, a.WinRate)
+	})
+	if len(decision.Moves) > 0 {
+		decision.Principal = principal(roots, decision.Moves[0].Move)
+	}
+	if bestScore == -1.0 {
+		log.Println("MCTS: No children visited, returning 'take' or 'pass' move as fallback.")
+		decision.Move = legalMoves[0]
+		for _, move := range legalMoves {
+			if move.Kind == MoveTake || move.Kind == MovePass {
+				decision.Move = move
+			}
+		}
+	}
+	if m.mistakeRate > 0 && m.rng.Float64() < m.mistakeRate {
+		others := slices.DeleteFunc(slices.Clone(legalMoves), func(move Move) bool { return move == decision.Move })
+		decision.Move = others[m.rng.IntN(len(others))]
+		log.Printf("MCTS: Deliberately playing %v", decision.Move)
+	}
+	return decision
+}
+
+// principalMinVisits is the number of playouts a move needs to carry the
+// principal variation on.
+const principalMinVisits = 10
+
+// principal returns the principal variation of the trees below roots,
+// starting with first: the most visited move at every step, summed over the
+// trees, while it has enough visits. Ties go to the move tried first.
+func principal(roots []*Node, first Move) []Move {
+	line := []Move{first}
+	nodes := roots
+	for {
+		var next []*Node
+		for _, node := range nodes {
+			if child := node.child(line[len(line)-1]); child != nil {
+				next = append(next, child)
+			}
+		}
+		nodes = next
+
+		var moves []Move
+		visits := make(map[Move]int)
+		for _, node := range nodes {
+			for _, child := range node.children {
+				if _, ok := visits[child.move]; !ok {
+					moves = append(moves, child.move)
+				}
+				visits[child.move] += child.visits
+			}
+		}
+		best, most := Move{}, 0
+		for _, move := range moves {
+			if visits[move] > most {
+				best, most = move, visits[move]
+			}
 		}
+		if most < principalMinVisits {
+			return line
+		}
+		line = append(line, best)
+	}
+}
+
+// solveEndgame solves board exactly if it is a small enough endgame. The
+// solver gets half of the time left, so the tree search can take over if it
+// fails.
+func (m *mcts) solveEndgame(ctx context.Context, board *Board) (EndgameResult, bool) {
+	if !m.tracking || m.endgameCards < 0 || !IsEndgame(board) || board.CardsInPlay() > m.endgameCards {
+		return EndgameResult{}, false
+	}
+	// The solver sees every hand, which the seat to move can only work out
+	// if every other card was seen discarded.
+	if m.knowledge == nil || len(m.knowledge.Discarded)+board.CardsInPlay() != m.dealt {
+		return EndgameResult{}, false
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Until(deadline)/2)
+		defer cancel()
+	}
+	result, err := SolveEndgame(ctx, m.engine, board, m.table(0))
+	if err != nil {
+		log.Println("MCTS: Endgame not solved:", err)
+		return EndgameResult{}, false
+	}
+	return result, true
+}
+
+// table returns the i-th transposition table, or nil if there are none.
+func (m *mcts) table(i int) *TranspositionTable {
+	if m.tables == nil {
+		return nil
+	}
+	return m.tables[i]
+}
 
-		// 2. Expansion
-		if len(node.untriedMoves) > 0 {
-			moveIndex := rand.IntN(len(node.untriedMoves))
-			move := node.untriedMoves[moveIndex]
-			node.untriedMoves = append(node.untriedMoves[:moveIndex], node.untriedMoves[moveIndex+1:]...)
+// search grows the tree below root until it has run the given number of
+// iterations, or until ctx is done if iterations is zero. It returns the
+// number of playouts completed. Only the tree, tt and rng are modified, so
+// several searches may share the board. The statistics of the nodes are
+// also kept in tt, if it is not nil, under the position each move leads to
+// as seat sees it, and a node reached by another order of moves starts out
+// with them.
+func (m *mcts) search(ctx context.Context, board *Board, seat Player, root *Node, tt *TranspositionTable, iterations int, rng *rand.Rand) int {
+	playouts := 0
+	for ; iterations <= 0 || playouts < iterations; playouts++ {
+		if ctx.Err() != nil {
+			break
+		}
+		node := root
+		// Every iteration deals the hidden cards anew, so the statistics
+		// are aggregated over many plausible deals.
+		simulationBoard := m.knowledge.Determinize(board, seat, rng)
 
-			m.engine.PlayMove(simulationBoard, move)
-			childNode := &Node{
-				move:         move,
-				parent:       node,
-				playerToMove: simulationBoard.Attacker,
-				untriedMoves: m.engine.GetLegalMoves(simulationBoard),
+		// 1. Selection & 2. Expansion
+		for {
+			if gameOver, _ := m.engine.CheckGameOver(simulationBoard); gameOver {
+				break
+			}
+			moves := m.engine.GetLegalMoves(simulationBoard)
+			if untried := node.untried(moves); len(untried) > 0 {
+				move := untried[rng.IntN(len(untried))]
+				m.engine.PlayMove(simulationBoard, move)
+				childNode := &Node{move: move, parent: node}
+				if tt != nil {
+					childNode.key = simulationBoard.InfoHash(seat) ^ zobrist.mover[move.Seat]
+				}
+				node.children = append(node.children, childNode)
+				node = childNode
+				break
 			}
-			node.children = append(node.children, childNode)
-			node = childNode
+			if len(moves) == 0 {
+				break
+			}
+			node = node.selectChild(moves, tt)
+			m.engine.PlayMove(simulationBoard, node.move)
 		}
 
 		// 3. Simulation
@@ -101,88 +520,106 @@ func (m *mcts) Solve(board *Board) Move {
 				// This should ideally not happen if GetLegalMoves always provides an option (e.g. take/pass)
 				break
 			}
-			randomMove := moves[rand.IntN(len(moves))]
-			m.engine.PlayMove(simulationBoard, randomMove)
+			m.engine.PlayMove(simulationBoard, m.rollout.Choose(simulationBoard, moves, rng))
 		}
 
 		// 4. Backpropagation
-		_, winner := m.engine.CheckGameOver(simulationBoard)
+		results := m.results(simulationBoard)
 		for node != nil {
-			node.update(winner)
+			result := results[node.move.Seat]
+			node.update(result)
+			if tt != nil && node.parent != nil {
+				e := tt.store(node.key)
+				e.wins += result
+				e.visits++
+			}
 			node = node.parent
 		}
 	}
+	return playouts
+}
 
-	// Return the move from the child with the highest win rate.
-	var bestMove Move
-	bestScore := -1.0
-	for _, child := range root.children {
-		if child.visits > 0 {
-			score := child.wins / float64(child.visits)
-			if score > bestScore {
-				bestScore = score
-				bestMove = child.move
-			}
+// results scores a simulated game for every seat: by how it ended, or by
+// the evaluator if it was cut short.
+func (m *mcts) results(board *Board) []float64 {
+	over, durak := m.engine.CheckGameOver(board)
+	results := make([]float64, len(board.Hands))
+	for p := range results {
+		if over || m.evaluator == nil {
+			results[p] = playoutResult(durak, Player(p))
+		} else {
+			results[p] = m.evaluator.Evaluate(board, Player(p))
 		}
 	}
+	return results
+}
 
-	if bestScore == -1.0 {
-		log.Println("MCTS: No children visited, returning 'take' move as fallback.")
-		for _, move := range legalMoves {
-			if move.take {
-				return move
-			}
-		}
-		// Fallback if 'take' is not a legal move for some reason.
-		if len(legalMoves) > 0 {
-			return legalMoves[0]
+// sameView reports whether two boards look the same to seat: the same
+// table, turn, deck size and hand sizes, and the same cards in seat's own
+// hand.
+func sameView(a, b *Board, seat Player) bool {
+	if a.Attacker != b.Attacker || a.Leader != b.Leader || a.Defender != b.Defender || a.Passes != b.Passes ||
+		a.Round != b.Round || a.TrumpCard != b.TrumpCard || len(a.Deck) != len(b.Deck) ||
+		len(a.Hands) != len(b.Hands) || !slices.Equal(a.Table, b.Table) ||
+		!slices.Equal(a.Hands[seat], b.Hands[seat]) {
+		return false
+	}
+	for p := range a.Hands {
+		if len(a.Hands[p]) != len(b.Hands[p]) {
+			return false
 		}
-		return Move{take: true}
 	}
+	return true
+}
 
-	// The AI is player 1. It is defending if the attacker is player 0.
-	isAIDefending := board.Attacker == 0
-
-	if isAIDefending && !bestMove.take && len(board.Table) > 0 {
-		// This is a defense move with a card. Let's validate it.
-		// The attacking card is the last one played on the table.
-		attackingCard := board.Table[len(board.Table)-1].c
-		chosenCard := bestMove.Card[0]
-		if !m.engine.CanBeat(attackingCard, chosenCard, board.TrumpSuit) {
-			log.Printf("MCTS chose an invalid move %v to beat %v. Forcing 'take'.", chosenCard, attackingCard)
-			// The chosen move is invalid. Find the 'take' move in legalMoves and return it.
-			for _, move := range legalMoves {
-				if move.take {
-					return move
-				}
-			}
-			// Fallback
-			return Move{take: true}
+// untried returns the legal moves that have no child yet.
+func (n *Node) untried(moves []Move) []Move {
+	var untried []Move
+	for _, move := range moves {
+		if n.child(move) == nil {
+			untried = append(untried, move)
 		}
 	}
+	return untried
+}
 
-	return bestMove
+// child returns the child reached by move, or nil.
+func (n *Node) child(move Move) *Node {
+	for _, child := range n.children {
+		if child.move == move {
+			return child
+		}
+	}
+	return nil
 }
 
-// selectChild selects a child node to explore using the UCB1 formula.
-func (n *Node) selectChild() *Node {
+// selectChild selects a child node to explore among those whose move is
+// legal, using the UCB1 formula with availability counts in place of the
+// parent's visits. Every player picks by their own win rate, taken from tt
+// where it has seen the position more often than the child.
+func (n *Node) selectChild(legal []Move, tt *TranspositionTable) *Node {
 	const c = 1.414 // sqrt(2)
 	bestScore := -1.0
 	var bestChild *Node
 
-	for _, child := range n.children {
+	for _, move := range legal {
+		child := n.child(move)
+		if child == nil {
+			continue
+		}
+		child.avail++
+		wins, visits := child.wins, child.visits
+		if tt != nil {
+			if e := tt.probe(child.key); e != nil && int(e.visits) > visits {
+				wins, visits = e.wins, int(e.visits)
+			}
+		}
 		var score float64
-		if child.visits == 0 {
+		if visits == 0 {
 			score = math.MaxFloat64 // Prioritize unvisited nodes
 		} else {
-			winRate := child.wins / float64(child.visits)
-			// If it's the opponent's turn at this node (player 0), they will try to maximize
-			// their win rate, which is 1 - our (AI's) win rate.
-			if n.playerToMove != 1 { // AI is player 1
-				winRate = 1.0 - winRate
-			}
-
-			explore := c * math.Sqrt(math.Log(float64(n.visits)) / float64(child.visits))
+			winRate := wins / float64(visits)
+			explore := c * math.Sqrt(math.Log(float64(child.avail))/float64(visits))
 			score = winRate + explore
 		}
 
@@ -194,13 +631,21 @@ func (n *Node) selectChild() *Node {
 	return bestChild
 }
 
-// update updates the node's statistics from a simulation result.
-// Wins are from the perspective of player 1 (the AI).
-func (n *Node) update(winner Player) {
+// update updates the node's statistics from a simulation result, scored
+// for the seat that played the node's move.
+func (n *Node) update(result float64) {
 	n.visits++
-	if winner == 1 { // AI is player 1
-		n.wins += 1.0
-	} else if winner == -1 { // Draw
-		n.wins += 0.5
+	n.wins += result
+}
+
+// playoutResult scores a simulated game for seat: 1 unless seat is the
+// durak, and a half for a draw.
+func playoutResult(durak, seat Player) float64 {
+	switch durak {
+	case -1:
+		return 0.5
+	case seat:
+		return 0
 	}
+	return 1
 }
@@ -0,0 +1,69 @@
+package durak
+
+import (
+	"context"
+	"math/rand/v2"
+	"reflect"
+	"testing"
+)
+
+// seededMoves plays the first decisions of a game dealt from a fixed shuffle
+// with a seeded search on 8 workers, and returns the statistics of each.
+func seededMoves(t *testing.T) [][]MoveStats {
+	t.Helper()
+	engine := NewEngine(DefaultRules())
+	engine.SetAI(NewMCTS(engine, MCTSConfig{Budget: Budget{Iterations: 3000}, Workers: 8, Seed: 42}))
+	board := engine.dealWith(rand.New(rand.NewPCG(1, 2)))
+
+	var moves [][]MoveStats
+	for range 3 {
+		decision := engine.AI.Solve(context.Background(), board.Copy())
+		if err := engine.Apply(board, decision.Move); err != nil {
+			t.Fatal(err)
+		}
+		moves = append(moves, decision.Moves)
+	}
+	return moves
+}
+
+func TestSeedIsReproducible(t *testing.T) {
+	want := seededMoves(t)
+	if len(want[0]) < 2 {
+		t.Fatalf("the first decision weighed %v, want a choice of moves", want[0])
+	}
+	for run := range 4 {
+		if got := seededMoves(t); !reflect.DeepEqual(got, want) {
+			t.Fatalf("run %d searched %v, want %v", run+2, got, want)
+		}
+	}
+}
+
+func TestReuseAcrossSeats(t *testing.T) {
+	rules := DefaultRules()
+	rules.Players = 3
+	engine := NewEngine(rules)
+	engine.SetAI(NewMCTS(engine, MCTSConfig{Budget: Budget{Iterations: 500}, Workers: 1, Seed: 7}))
+	board := engine.dealWith(rand.New(rand.NewPCG(3, 4)))
+
+	// Count the searches that picked up a tree although another seat was
+	// searched for in between.
+	reused := 0
+	last := Player(-1)
+	for over, _ := engine.CheckGameOver(board); !over; over, _ = engine.CheckGameOver(board) {
+		seat := board.ToMove()
+		decision := engine.AI.Solve(context.Background(), board.Copy())
+		if err := engine.Apply(board, decision.Move); err != nil {
+			t.Fatal(err)
+		}
+		if decision.Playouts == 0 {
+			continue
+		}
+		if last >= 0 && last != seat && decision.Reused > 0 {
+			reused++
+		}
+		last = seat
+	}
+	if reused == 0 {
+		t.Error("no search reused its seat's tree after a search for another seat")
+	}
+}
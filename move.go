@@ -0,0 +1,133 @@
+package durak
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MoveKind says what a move does.
+type MoveKind int
+
+const (
+	// MoveAttack opens a round with a card.
+	MoveAttack MoveKind = iota
+	// MoveThrowIn adds a card of a rank already on the table.
+	MoveThrowIn
+	// MoveDefend covers the attack card in the Target slot.
+	MoveDefend
+	// MoveTake picks up every card on the table.
+	MoveTake
+	// MovePass declines to throw in more cards ("bito" once everybody
+	// passed).
+	MovePass
+	// MoveTransfer adds a card of the attack's rank and passes the attack on
+	// to the next player.
+	MoveTransfer
+)
+
+var moveKindNames = [...]string{
+	MoveAttack:   "attack",
+	MoveThrowIn:  "throw-in",
+	MoveDefend:   "defend",
+	MoveTake:     "take",
+	MovePass:     "pass",
+	MoveTransfer: "transfer",
+}
+
+// String returns the name of the move kind as used in the move format.
+func (k MoveKind) String() string {
+	if k < 0 || int(k) >= len(moveKindNames) {
+		return "?"
+	}
+	return moveKindNames[k]
+}
+
+// HasCard reports whether moves of this kind play a card.
+func (k MoveKind) HasCard() bool {
+	return k != MoveTake && k != MovePass
+}
+
+// Move is a single action taken by a seat.
+type Move struct {
+	Kind MoveKind
+	// Seat is the player making the move.
+	Seat Player
+	// Card is the card played. It is unset for MoveTake and MovePass.
+	Card Card
+	// Target is the table slot a MoveDefend covers.
+	Target int
+}
+
+// String formats the move as "<seat> <kind> [card] [target]", e.g.
+// "1 attack 6♠", "0 defend 7♠ 2" or "1 pass". ParseMove reads it back.
+func (m Move) String() string {
+	switch {
+	case m.Kind == MoveDefend:
+		return fmt.Sprintf("%d %s %s %d", m.Seat, m.Kind, m.Card, m.Target)
+	case m.Kind.HasCard():
+		return fmt.Sprintf("%d %s %s", m.Seat, m.Kind, m.Card)
+	default:
+		return fmt.Sprintf("%d %s", m.Seat, m.Kind)
+	}
+}
+
+// MarshalText returns the move as written by String, so that it encodes to
+// JSON as a string.
+func (m Move) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText parses a move as ParseMove does.
+func (m *Move) UnmarshalText(text []byte) error {
+	move, err := ParseMove(string(text))
+	if err != nil {
+		return err
+	}
+	*m = move
+	return nil
+}
+
+// ParseMove parses a move in the format written by Move.String. Cards may use
+// suit letters as well as suit symbols, e.g. "0 defend 7S 2".
+func ParseMove(s string) (Move, error) {
+	fields := strings.Fields(s)
+	if len(fields) < 2 {
+		return Move{}, fmt.Errorf("move %q: want \"<seat> <kind> [card] [target]\"", s)
+	}
+	seat, err := strconv.Atoi(fields[0])
+	if err != nil || seat < 0 {
+		return Move{}, fmt.Errorf("move %q: bad seat %q", s, fields[0])
+	}
+	m := Move{Seat: Player(seat), Kind: -1}
+	for k, name := range moveKindNames {
+		if fields[1] == name {
+			m.Kind = MoveKind(k)
+		}
+	}
+	if m.Kind < 0 {
+		return Move{}, fmt.Errorf("move %q: unknown kind %q", s, fields[1])
+	}
+
+	want := 2
+	if m.Kind.HasCard() {
+		want++
+	}
+	if m.Kind == MoveDefend {
+		want++
+	}
+	if len(fields) != want {
+		return Move{}, fmt.Errorf("move %q: %s takes %d fields", s, m.Kind, want)
+	}
+	if m.Kind.HasCard() {
+		if m.Card, err = ParseCard(fields[2]); err != nil {
+			return Move{}, fmt.Errorf("move %q: %w", s, err)
+		}
+	}
+	if m.Kind == MoveDefend {
+		if m.Target, err = strconv.Atoi(fields[3]); err != nil || m.Target < 0 {
+			return Move{}, fmt.Errorf("move %q: bad target %q", s, fields[3])
+		}
+	}
+	return m, nil
+}
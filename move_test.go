@@ -0,0 +1,69 @@
+package durak
+
+import "testing"
+
+func TestMoveRoundTrip(t *testing.T) {
+	for kind := range MoveKind(len(moveKindNames)) {
+		for _, card := range Deck52.Cards() {
+			m := Move{Kind: kind, Seat: 3}
+			if kind.HasCard() {
+				m.Card = card
+			}
+			if kind == MoveDefend {
+				m.Target = 5
+			}
+			got, err := ParseMove(m.String())
+			if err != nil {
+				t.Fatalf("ParseMove(%q): %v", m.String(), err)
+			}
+			if got != m {
+				t.Fatalf("ParseMove(%q) = %#v, want %#v", m.String(), got, m)
+			}
+			text, err := m.MarshalText()
+			if err != nil {
+				t.Fatalf("MarshalText(%v): %v", m, err)
+			}
+			var back Move
+			if err := back.UnmarshalText(text); err != nil || back != m {
+				t.Fatalf("UnmarshalText(%q) = %v, %v, want %v", text, back, err, m)
+			}
+			if !kind.HasCard() {
+				break
+			}
+		}
+	}
+}
+
+func TestParseMoveLetters(t *testing.T) {
+	got, err := ParseMove("2 defend 10h 1")
+	want := Move{Kind: MoveDefend, Seat: 2, Card: Card{Rank: Ten, Suit: Hearts}, Target: 1}
+	if err != nil || got != want {
+		t.Errorf("ParseMove = %v, %v, want %v", got, err, want)
+	}
+}
+
+func TestParseMoveErrors(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"1",
+		"-1 attack 6♠",
+		"x attack 6♠",
+		"1 charge 6♠",
+		"1 attack",
+		"1 attack 6♠ 0",
+		"1 attack 1♠",
+		"1 attack 6x",
+		"1 throw-in",
+		"1 defend 7♠",
+		"1 defend 7♠ -1",
+		"1 defend 7♠ x",
+		"1 defend 7♠ 0 0",
+		"1 take 6♠",
+		"1 pass 0",
+		"1 transfer",
+	} {
+		if m, err := ParseMove(s); err == nil {
+			t.Errorf("ParseMove(%q) = %v, want an error", s, m)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package durak
+
+import (
+	"fmt"
+	"sync"
+)
+
+// AIInfo describes an AI that can be picked by name.
+type AIInfo struct {
+	// Name picks the AI, e.g. on the command line.
+	Name string
+	// Description tells players what to expect, in a few words.
+	Description string
+	// New returns a fresh AI for a game run by engine.
+	New func(engine GameEngine) AI
+}
+
+var (
+	registryMu sync.Mutex
+	registry   []AIInfo
+)
+
+func init() {
+	for l := Easy; l <= Expert; l++ {
+		RegisterAI(AIInfo{
+			Name:        l.String(),
+			Description: levelDescriptions[l],
+			New:         func(engine GameEngine) AI { return NewMCTS(engine, l.Config()) },
+		})
+	}
+	RegisterAI(AIInfo{Name: "greedy", Description: "always plays its lowest card", New: NewGreedyBot})
+	RegisterAI(AIInfo{Name: "hoarder", Description: "keeps its trumps while the deck lasts", New: NewTrumpHoarderBot})
+	RegisterAI(AIInfo{Name: "aggressive", Description: "throws in everything it can", New: NewAggressiveBot})
+}
+
+// levelDescriptions describe the levels in the registry.
+var levelDescriptions = map[Level]string{
+	Easy:   "forgetful and careless",
+	Normal: "counts cards, slips now and then",
+	Hard:   "thinks for most of a second, plays out sensibly",
+	Expert: "thinks for two seconds, on every core",
+}
+
+// RegisterAI makes an AI available by name. The levels and the rule-based
+// bots are registered already. It panics if the name is taken.
+func RegisterAI(info AIInfo) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for _, other := range registry {
+		if other.Name == info.Name {
+			panic("durak: RegisterAI called twice for " + info.Name)
+		}
+	}
+	registry = append(registry, info)
+}
+
+// RegisteredAIs returns the registered AIs in the order they were
+// registered.
+func RegisteredAIs() []AIInfo {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	return append([]AIInfo(nil), registry...)
+}
+
+// LookupAI returns the registered AI called name.
+func LookupAI(name string) (AIInfo, bool) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for _, info := range registry {
+		if info.Name == name {
+			return info, true
+		}
+	}
+	return AIInfo{}, false
+}
+
+// NewAI returns a fresh instance of the registered AI called name.
+func NewAI(name string, engine GameEngine) (AI, error) {
+	info, ok := LookupAI(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown AI %q", name)
+	}
+	return info.New(engine), nil
+}
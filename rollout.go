@@ -0,0 +1,83 @@
+package durak
+
+import "math/rand/v2"
+
+// RolloutPolicy plays the moves of a simulated game once the search has
+// left its tree. The search calls it from several goroutines at once, each
+// with a generator of its own.
+type RolloutPolicy interface {
+	// Choose returns one of moves, the legal moves on board.
+	Choose(board *Board, moves []Move, rng *rand.Rand) Move
+}
+
+// RandomRollout picks every move uniformly at random. It is fast, but its
+// playouts take cards for no reason and waste trumps.
+type RandomRollout struct{}
+
+// Choose returns a random move.
+func (RandomRollout) Choose(board *Board, moves []Move, rng *rand.Rand) Move {
+	return moves[rng.IntN(len(moves))]
+}
+
+// HeuristicRollout picks moves at random, weighted the way a sensible player
+// would: it beats with the cheapest card that does, saves its trumps while
+// the deck lasts, throws in low cards rather than high ones and takes only
+// when it has nothing to beat with. Every move keeps some chance of being
+// played, so the playouts still explore.
+type HeuristicRollout struct{}
+
+// Weights of moves that do not spend a card, on the scale of cardWeight.
+const (
+	passWeight = 1.0 / 81  // as likely as throwing in a plain 10
+	takeWeight = 1.0 / 1e4 // when the defender could beat or transfer
+)
+
+// Choose returns a random move, weighted by how sensible it is.
+func (HeuristicRollout) Choose(board *Board, moves []Move, rng *rand.Rand) Move {
+	weights := make([]float64, len(moves))
+	forced := len(moves) == 1
+	total := 0.0
+	for i, move := range moves {
+		switch move.Kind {
+		case MovePass:
+			weights[i] = passWeight
+		case MoveTake:
+			weights[i] = takeWeight
+			if forced {
+				weights[i] = 1
+			}
+		default:
+			weights[i] = cardWeight(board, move.Card)
+		}
+		total += weights[i]
+	}
+
+	x := rng.Float64() * total
+	for i, w := range weights {
+		if x < w {
+			return moves[i]
+		}
+		x -= w
+	}
+	return moves[len(moves)-1]
+}
+
+// cardCost rates how much a card is worth keeping: its rank, and a trump
+// above any plain card, more so while the deck lasts.
+func cardCost(board *Board, card Card) int {
+	cost := int(card.Rank - Two)
+	if card.Suit == board.TrumpSuit {
+		cost += 13
+		if len(board.Deck) > 0 {
+			cost += 6
+		}
+	}
+	return cost
+}
+
+// cardWeight is the weight of playing a card: cheap cards are played far more
+// readily than dear ones.
+func cardWeight(board *Board, card Card) float64 {
+	c := float64(1 + cardCost(board, card))
+	return 1 / (c * c)
+}
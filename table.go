@@ -0,0 +1,76 @@
+package durak
+
+// TranspositionTable remembers what a search learned about positions, keyed
+// by their Zobrist hash, so that a position reached by another order of
+// moves is not searched afresh. It has a fixed size; when it is full, new
+// positions push out the ones with the least work behind them. A table is
+// not safe for concurrent use.
+type TranspositionTable struct {
+	slots []ttSlot
+	mask  uint64
+}
+
+// ttSlot is an entry of the table. The tree search keeps win statistics,
+// the endgame solver a bounded score.
+type ttSlot struct {
+	key    uint64
+	wins   float64
+	visits int32 // playouts, or positions searched by the endgame solver
+	score  int16
+	bound  int8
+	used   bool
+}
+
+// ttSlotBytes is the size of a ttSlot.
+const ttSlotBytes = 24
+
+// NewTranspositionTable returns a table that takes up at most the given
+// number of bytes, and at least two entries.
+func NewTranspositionTable(bytes int) *TranspositionTable {
+	n := uint64(2)
+	for n*2*ttSlotBytes <= uint64(max(bytes, 0)) {
+		n *= 2
+	}
+	return &TranspositionTable{slots: make([]ttSlot, n), mask: n - 1}
+}
+
+// Len returns the number of positions the table can hold.
+func (t *TranspositionTable) Len() int {
+	return len(t.slots)
+}
+
+// Clear forgets every position.
+func (t *TranspositionTable) Clear() {
+	clear(t.slots)
+}
+
+// probe returns the entry for key, or nil.
+func (t *TranspositionTable) probe(key uint64) *ttSlot {
+	i := key & t.mask
+	for _, j := range [2]uint64{i, i ^ 1} {
+		if s := &t.slots[j]; s.used && s.key == key {
+			return s
+		}
+	}
+	return nil
+}
+
+// store returns the entry for key, making room for it if it is new. Each
+// key may go into one of two slots; a new key takes the free one or the one
+// with fewer visits.
+func (t *TranspositionTable) store(key uint64) *ttSlot {
+	i := key & t.mask
+	a, b := &t.slots[i], &t.slots[i^1]
+	switch {
+	case a.used && a.key == key:
+		return a
+	case b.used && b.key == key:
+		return b
+	}
+	s := a
+	if !b.used || a.used && b.visits < a.visits {
+		s = b
+	}
+	*s = ttSlot{key: key, used: true}
+	return s
+}
@@ -0,0 +1,62 @@
+package durak
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewTranspositionTableSize(t *testing.T) {
+	if size := reflect.TypeFor[ttSlot]().Size(); size != ttSlotBytes {
+		t.Fatalf("a ttSlot takes %d bytes, ttSlotBytes says %d", size, ttSlotBytes)
+	}
+	tests := []struct {
+		bytes int
+		slots int
+	}{
+		{bytes: -1, slots: 2},
+		{bytes: 0, slots: 2},
+		{bytes: 4 * ttSlotBytes, slots: 4},
+		{bytes: 5 * ttSlotBytes, slots: 4},
+		{bytes: 8*ttSlotBytes - 1, slots: 4},
+		{bytes: 1 << 20, slots: 1 << 15},
+	}
+	for _, tt := range tests {
+		table := NewTranspositionTable(tt.bytes)
+		if table.Len() != tt.slots {
+			t.Errorf("NewTranspositionTable(%d) holds %d positions, want %d", tt.bytes, table.Len(), tt.slots)
+		}
+		if tt.slots > 2 && table.Len()*ttSlotBytes > tt.bytes {
+			t.Errorf("NewTranspositionTable(%d) takes %d bytes", tt.bytes, table.Len()*ttSlotBytes)
+		}
+	}
+}
+
+func TestTranspositionTableReplacement(t *testing.T) {
+	table := NewTranspositionTable(4 * ttSlotBytes)
+	// Keys 1, 5 and 9 compete for slots 0 and 1.
+	table.store(1).visits = 10
+	table.store(5).visits = 3
+	if e := table.probe(1); e == nil || e.visits != 10 {
+		t.Fatalf("probe(1) = %v, want the entry stored", e)
+	}
+	if e := table.store(5); e.visits != 3 {
+		t.Fatalf("store(5) made a new entry over the one stored")
+	}
+
+	// A third key pushes out the one with fewer visits.
+	table.store(9).visits = 1
+	if table.probe(5) != nil {
+		t.Error("key 5 is still stored after it was pushed out")
+	}
+	if table.probe(1) == nil || table.probe(9) == nil {
+		t.Error("keys 1 and 9 should both be stored")
+	}
+	if table.probe(3) != nil {
+		t.Error("probe found a key never stored")
+	}
+
+	table.Clear()
+	if table.probe(1) != nil || table.probe(9) != nil {
+		t.Error("Clear left keys stored")
+	}
+}
@@ -0,0 +1,301 @@
+package durak
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sync"
+)
+
+// Tournament plays AIs against each other, two at a time, to tell which is
+// stronger. Every pair of entrants plays the same seeded deals, each deal
+// twice with the seats swapped, so that neither side is luckier with the
+// cards. With more than two players the two sides take turns around the
+// table.
+type Tournament struct {
+	Rules Rules
+	// Entrants are the AIs taking part, at least two. Each game gets fresh
+	// instances from New.
+	Entrants []AIInfo
+	// Deals is the number of deals every pair plays, twice each.
+	Deals int
+	// Seed picks the deals: the same seed deals the same games again.
+	Seed uint64
+	// Parallel is the number of games played at once. Zero means one.
+	Parallel int
+	// Progress, if set, is called after every game with the number of games
+	// finished and the total. Calls do not overlap.
+	Progress func(done, total int)
+}
+
+// tournamentMoveLimit ends a game as a draw if it runs this long, which only
+// an AI playing in circles manages.
+const tournamentMoveLimit = 2000
+
+// Score counts the games one side won, drew and lost.
+type Score struct {
+	Wins, Draws, Losses int
+}
+
+// Games returns the number of games played.
+func (s Score) Games() int {
+	return s.Wins + s.Draws + s.Losses
+}
+
+// Rate returns the share of the points won, a draw counting half.
+func (s Score) Rate() float64 {
+	if s.Games() == 0 {
+		return 0.5
+	}
+	return (float64(s.Wins) + float64(s.Draws)/2) / float64(s.Games())
+}
+
+// Interval returns the 95% Wilson score interval of Rate.
+func (s Score) Interval() (lo, hi float64) {
+	return wilson(s.Rate(), s.Games())
+}
+
+// wilson returns the 95% Wilson score interval of a share p of n trials.
+func wilson(p float64, trials int) (lo, hi float64) {
+	n := float64(trials)
+	if n == 0 {
+		return 0, 1
+	}
+	const z = 1.96
+	center := (p + z*z/(2*n)) / (1 + z*z/n)
+	spread := z / (1 + z*z/n) * math.Sqrt(p*(1-p)/n+z*z/(4*n*n))
+	return max(center-spread, 0), min(center+spread, 1)
+}
+
+// EloDiff returns the difference in Elo rating that an expected share of
+// the points of rate implies. It is infinite for a rate of 0 or 1.
+func EloDiff(rate float64) float64 {
+	return -400 * math.Log10(1/rate-1)
+}
+
+// Match is the result of the games between two entrants.
+type Match struct {
+	A, B string
+	// Score is A's.
+	Score Score
+}
+
+// Rating is an entrant's standing in a tournament.
+type Rating struct {
+	Name  string
+	Score Score
+	// Elo is the entrant's rating, such that the ratings average zero.
+	Elo float64
+}
+
+// TournamentResult is the outcome of a tournament.
+type TournamentResult struct {
+	// Matches are in the order the pairs were drawn up: every entrant
+	// against each one after it.
+	Matches []Match
+	// Ratings are the best first.
+	Ratings []Rating
+}
+
+// Run plays the tournament. It stops early with ctx.Err() if ctx is done.
+func (t *Tournament) Run(ctx context.Context) (TournamentResult, error) {
+	if len(t.Entrants) < 2 {
+		return TournamentResult{}, errors.New("a tournament needs at least two entrants")
+	}
+	for i, info := range t.Entrants {
+		if slices.ContainsFunc(t.Entrants[:i], func(other AIInfo) bool { return other.Name == info.Name }) {
+			return TournamentResult{}, fmt.Errorf("%s entered twice", info.Name)
+		}
+	}
+	if err := t.Rules.Validate(); err != nil {
+		return TournamentResult{}, err
+	}
+
+	type game struct {
+		match  int
+		a, b   AIInfo
+		deal   int
+		mirror bool
+	}
+	var games []game
+	var result TournamentResult
+	for i, a := range t.Entrants {
+		for _, b := range t.Entrants[i+1:] {
+			for deal := range t.Deals {
+				for _, mirror := range []bool{false, true} {
+					games = append(games, game{match: len(result.Matches), a: a, b: b, deal: deal, mirror: mirror})
+				}
+			}
+			result.Matches = append(result.Matches, Match{A: a.Name, B: b.Name})
+		}
+	}
+
+	jobs := make(chan game)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	done := 0
+	for range max(t.Parallel, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for g := range jobs {
+				outcome, ok := t.play(ctx, g.a, g.b, g.deal, g.mirror)
+				if !ok {
+					continue
+				}
+				mu.Lock()
+				score := &result.Matches[g.match].Score
+				switch outcome {
+				case OutcomeWin:
+					score.Wins++
+				case OutcomeDraw:
+					score.Draws++
+				default:
+					score.Losses++
+				}
+				done++
+				if t.Progress != nil {
+					t.Progress(done, len(games))
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, g := range games {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- g
+	}
+	close(jobs)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return TournamentResult{}, err
+	}
+
+	result.Ratings = rate(t.Entrants, result.Matches)
+	return result, nil
+}
+
+// play plays a deal between a and b and returns how it ended for a. Side a
+// takes the even seats, or the odd ones if mirror is set. It reports false
+// if ctx was done before the game ended.
+func (t *Tournament) play(ctx context.Context, a, b AIInfo, deal int, mirror bool) (Outcome, bool) {
+	engine := &Engine{Rules: t.Rules}
+	aiA, aiB := a.New(engine), b.New(engine)
+	plays := func(p Player) bool { return (int(p)%2 == 0) != mirror }
+	for p := range t.Rules.Players {
+		if plays(Player(p)) {
+			engine.SetSeatAI(Player(p), aiA)
+		} else {
+			engine.SetSeatAI(Player(p), aiB)
+		}
+	}
+
+	board := engine.dealWith(rand.New(rand.NewPCG(t.Seed, uint64(deal))))
+
+	for range tournamentMoveLimit {
+		if ctx.Err() != nil {
+			return 0, false
+		}
+		if over, durak := engine.CheckGameOver(board); over {
+			switch {
+			case durak == -1:
+				return OutcomeDraw, true
+			case plays(durak):
+				return OutcomeLoss, true
+			}
+			return OutcomeWin, true
+		}
+		engine.AITurn(ctx, board)
+	}
+	log.Printf("Tournament: %s vs %s, deal %d, stopped after %d moves", a.Name, b.Name, deal, tournamentMoveLimit)
+	return OutcomeDraw, true
+}
+
+// rate fits Elo ratings to the matches by maximum likelihood under the
+// Bradley-Terry model, a draw counting as half a win for each side. Every
+// match starts with one draw, so that a clean sweep still gets a finite
+// rating.
+func rate(entrants []AIInfo, matches []Match) []Rating {
+	index := make(map[string]int)
+	ratings := make([]Rating, len(entrants))
+	for i, info := range entrants {
+		index[info.Name] = i
+		ratings[i].Name = info.Name
+	}
+	points := make([]float64, len(entrants))
+	games := make([][]float64, len(entrants))
+	for i := range games {
+		games[i] = make([]float64, len(entrants))
+	}
+	for _, m := range matches {
+		a, b := index[m.A], index[m.B]
+		s := m.Score
+		ratings[a].Score.Wins += s.Wins
+		ratings[a].Score.Draws += s.Draws
+		ratings[a].Score.Losses += s.Losses
+		ratings[b].Score.Wins += s.Losses
+		ratings[b].Score.Draws += s.Draws
+		ratings[b].Score.Losses += s.Wins
+		points[a] += float64(s.Wins) + float64(s.Draws)/2 + 0.5
+		points[b] += float64(s.Losses) + float64(s.Draws)/2 + 0.5
+		n := float64(s.Games() + 1)
+		games[a][b] += n
+		games[b][a] += n
+	}
+
+	// Minorization-maximization: each step moves every strength to where
+	// its expected points match its actual points, given the others.
+	strength := make([]float64, len(entrants))
+	for i := range strength {
+		strength[i] = 1
+	}
+	for range 1000 {
+		next := make([]float64, len(strength))
+		change := 0.0
+		for i := range strength {
+			var sum float64
+			for j, n := range games[i] {
+				if n > 0 {
+					sum += n / (strength[i] + strength[j])
+				}
+			}
+			next[i] = strength[i]
+			if sum > 0 {
+				next[i] = points[i] / sum
+			}
+		}
+		// Fix the geometric mean at one, so the ratings average zero.
+		var logSum float64
+		for _, s := range next {
+			logSum += math.Log(s)
+		}
+		mean := math.Exp(logSum / float64(len(next)))
+		for i := range next {
+			next[i] /= mean
+			change = max(change, math.Abs(math.Log(next[i]/strength[i])))
+		}
+		strength = next
+		if change < 1e-9 {
+			break
+		}
+	}
+	for i := range ratings {
+		ratings[i].Elo = 400 * math.Log10(strength[i])
+	}
+	slices.SortStableFunc(ratings, func(a, b Rating) int {
+		switch {
+		case a.Elo > b.Elo:
+			return -1
+		case a.Elo < b.Elo:
+			return 1
+		}
+		return 0
+	})
+	return ratings
+}
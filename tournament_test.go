@@ -0,0 +1,79 @@
+package durak
+
+import (
+	"math"
+	"testing"
+)
+
+func near(a, b, tolerance float64) bool {
+	return math.Abs(a-b) <= tolerance
+}
+
+func TestEloDiff(t *testing.T) {
+	for _, tt := range []struct{ rate, want float64 }{
+		{0.5, 0},
+		{0.75, 190.85},
+		{0.25, -190.85},
+		{0.9, 381.70},
+	} {
+		if got := EloDiff(tt.rate); !near(got, tt.want, 0.01) {
+			t.Errorf("EloDiff(%v) = %.2f, want %.2f", tt.rate, got, tt.want)
+		}
+	}
+	if !math.IsInf(EloDiff(1), 1) || !math.IsInf(EloDiff(0), -1) {
+		t.Errorf("EloDiff(1), EloDiff(0) = %v, %v, want infinite", EloDiff(1), EloDiff(0))
+	}
+}
+
+func TestWilson(t *testing.T) {
+	for _, tt := range []struct {
+		p      float64
+		trials int
+		lo, hi float64
+	}{
+		{0.5, 100, 0.4038, 0.5962},
+		{0.75, 40, 0.5980, 0.8581},
+		{1, 10, 0.7225, 1},
+		{0, 10, 0, 0.2775},
+		{0.5, 0, 0, 1},
+	} {
+		lo, hi := wilson(tt.p, tt.trials)
+		if !near(lo, tt.lo, 1e-4) || !near(hi, tt.hi, 1e-4) {
+			t.Errorf("wilson(%v, %d) = %.4f, %.4f, want %.4f, %.4f", tt.p, tt.trials, lo, hi, tt.lo, tt.hi)
+		}
+	}
+}
+
+func TestRate(t *testing.T) {
+	entrants := []AIInfo{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+
+	// With one virtual draw, a's 3-1 against b is a 70% score.
+	ratings := rate(entrants[:2], []Match{{A: "a", B: "b", Score: Score{Wins: 3, Losses: 1}}})
+	if ratings[0].Name != "a" || !near(ratings[0].Elo-ratings[1].Elo, EloDiff(0.7), 0.01) {
+		t.Errorf("ratings = %+v, want a %.1f Elo above b", ratings, EloDiff(0.7))
+	}
+	if !near(ratings[0].Elo+ratings[1].Elo, 0, 1e-6) {
+		t.Errorf("ratings = %+v, want them to average zero", ratings)
+	}
+	if ratings[1].Score != (Score{Wins: 1, Losses: 3}) {
+		t.Errorf("b's score = %+v, want 1 win and 3 losses", ratings[1].Score)
+	}
+
+	// A clean sweep is rated like 20.5 points out of 21.
+	ratings = rate(entrants, []Match{
+		{A: "a", B: "b", Score: Score{Wins: 20}},
+		{A: "a", B: "c", Score: Score{Wins: 20}},
+		{A: "b", B: "c", Score: Score{Draws: 20}},
+	})
+	for _, r := range ratings {
+		if math.IsInf(r.Elo, 0) || math.IsNaN(r.Elo) {
+			t.Fatalf("ratings = %+v, want finite ratings", ratings)
+		}
+	}
+	if ratings[0].Name != "a" || !near(ratings[1].Elo, ratings[2].Elo, 1e-6) {
+		t.Errorf("ratings = %+v, want a first and b and c level", ratings)
+	}
+	if diff := ratings[0].Elo - ratings[1].Elo; !near(diff, EloDiff(20.5/21), 0.01) {
+		t.Errorf("a is %.1f Elo above the others, want %.1f", diff, EloDiff(20.5/21))
+	}
+}
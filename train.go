@@ -0,0 +1,174 @@
+package durak
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"slices"
+)
+
+// TrainConfig configures Train.
+type TrainConfig struct {
+	Rules Rules
+	// Games is the number of self-play games. Zero means 20000.
+	Games int
+	// Positions is the number of positions picked at random from each
+	// game to learn from. Positions of one game look much alike, so a few
+	// teach nearly as much as all of them and take far less memory. Zero
+	// means 4.
+	Positions int
+	// Policy plays every seat of the self-play games. Nil means
+	// HeuristicRollout.
+	Policy RolloutPolicy
+	// Epochs is the number of passes over the positions. Zero means 20.
+	Epochs int
+	// LearningRate is the step size the gradient descent starts with. It
+	// falls to nothing over the epochs. Zero means 0.01.
+	LearningRate float64
+	// Seed makes the training reproducible. Zero seeds from the system.
+	Seed uint64
+	// Progress, if set, is called after every epoch with the log loss on
+	// the games held back from training.
+	Progress func(epoch int, loss float64)
+}
+
+// trainSample is a position seen by one seat and how the game ended for it.
+type trainSample struct {
+	features []float64
+	result   float64
+}
+
+// Train plays games in which one policy plays every seat and fits Weights to
+// how they ended: for a few positions of every game, and every seat still in
+// the game, the weights learn to predict whether the seat got away. A tenth
+// of the games is held back to measure the loss reported to Progress. It
+// stops with ctx.Err() if ctx is done.
+func Train(ctx context.Context, cfg TrainConfig) (*Weights, error) {
+	if err := cfg.Rules.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.Games == 0 {
+		cfg.Games = 20000
+	}
+	if cfg.Positions < 0 {
+		return nil, errors.New("cannot pick a negative number of positions")
+	}
+	if cfg.Positions == 0 {
+		cfg.Positions = 4
+	}
+	if cfg.Policy == nil {
+		cfg.Policy = HeuristicRollout{}
+	}
+	if cfg.Epochs == 0 {
+		cfg.Epochs = 20
+	}
+	if cfg.LearningRate == 0 {
+		cfg.LearningRate = 0.01
+	}
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	rng := rand.New(rand.NewPCG(seed, seed))
+
+	var train, test []trainSample
+	engine := &Engine{Rules: cfg.Rules}
+	for g := range cfg.Games {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		samples := selfPlay(engine, cfg.Policy, cfg.Positions, rng)
+		if g%10 == 9 {
+			test = append(test, samples...)
+		} else {
+			train = append(train, samples...)
+		}
+	}
+	if len(train) == 0 {
+		return nil, errors.New("no positions to train on")
+	}
+
+	w := &Weights{
+		Version:  WeightsVersion,
+		Features: slices.Clone(featureNames),
+		Weights:  make([]float64, len(featureNames)),
+		Games:    cfg.Games,
+	}
+	const l2 = 1e-4 // keeps the weights of rare features small
+	for epoch := range cfg.Epochs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		rng.Shuffle(len(train), func(i, j int) { train[i], train[j] = train[j], train[i] })
+		rate := cfg.LearningRate * float64(cfg.Epochs-epoch) / float64(cfg.Epochs)
+		for _, s := range train {
+			// The gradient of the log loss of a logistic model.
+			g := sigmoid(w.score(s.features)) - s.result
+			w.Bias -= rate * g
+			for i, v := range s.features {
+				w.Weights[i] -= rate * (g*v + l2*w.Weights[i])
+			}
+		}
+		if cfg.Progress != nil {
+			cfg.Progress(epoch+1, logLoss(w, test))
+		}
+	}
+	return w, nil
+}
+
+// trainMoveLimit ends a self-play game that runs this long, which only a
+// policy playing in circles manages.
+const trainMoveLimit = 2000
+
+// selfPlay plays a game with policy in every seat and returns n of its
+// positions, picked at random. A game that does not end is thrown away.
+func selfPlay(engine *Engine, policy RolloutPolicy, n int, rng *rand.Rand) []trainSample {
+	board := engine.dealWith(rng)
+	type position struct {
+		board *Board
+		seats []Player
+	}
+	var positions []position
+	for range trainMoveLimit {
+		if over, durak := engine.CheckGameOver(board); over {
+			rng.Shuffle(len(positions), func(i, j int) {
+				positions[i], positions[j] = positions[j], positions[i]
+			})
+			var samples []trainSample
+			for _, pos := range positions[:min(n, len(positions))] {
+				for _, seat := range pos.seats {
+					samples = append(samples, trainSample{
+						features: features(pos.board, seat),
+						result:   playoutResult(durak, seat),
+					})
+				}
+			}
+			return samples
+		}
+		var seats []Player
+		for p, hand := range board.Hands {
+			if len(hand) > 0 || len(board.Deck) > 0 {
+				seats = append(seats, Player(p))
+			}
+		}
+		positions = append(positions, position{board.Copy(), seats})
+		moves := engine.GetLegalMoves(board)
+		engine.PlayMove(board, policy.Choose(board, moves, rng))
+	}
+	return nil
+}
+
+// logLoss returns the average log loss of w on samples.
+func logLoss(w *Weights, samples []trainSample) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	const eps = 1e-12
+	var sum float64
+	for _, s := range samples {
+		p := sigmoid(w.score(s.features))
+		sum -= s.result*math.Log(p+eps) + (1-s.result)*math.Log(1-p+eps)
+	}
+	return sum / float64(len(samples))
+}
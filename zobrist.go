@@ -0,0 +1,115 @@
+package durak
+
+import "math/rand/v2"
+
+// Zobrist keys: one random number per feature of a position, XORed together
+// for the features present. They are drawn from a fixed seed so hashes are
+// the same in every run.
+var zobrist struct {
+	hand       [6][52]uint64 // card in a seat's hand
+	handSize   [6][53]uint64 // number of cards in a seat's hand
+	uncovered  [52]uint64    // attack card waiting to be beaten
+	covered    [52]uint64    // card of a beaten pair
+	inPlay     [52]uint64    // card not yet discarded
+	trump      [52]uint64    // the face-up trump card
+	attacker   [6]uint64
+	leader     [6]uint64
+	defender   [6]uint64
+	mover      [6]uint64 // seat whose move led to a node
+	seat       [6]uint64 // seat a search or table entry belongs to
+	passes     [7]uint64
+	deckSize   [53]uint64
+	firstRound uint64
+}
+
+func init() {
+	rng := rand.New(rand.NewPCG(0x6475726b, 0x7a6f6272))
+	fill := func(keys []uint64) {
+		for i := range keys {
+			keys[i] = rng.Uint64()
+		}
+	}
+	for p := range zobrist.hand {
+		fill(zobrist.hand[p][:])
+		fill(zobrist.handSize[p][:])
+	}
+	fill(zobrist.uncovered[:])
+	fill(zobrist.covered[:])
+	fill(zobrist.inPlay[:])
+	fill(zobrist.trump[:])
+	fill(zobrist.attacker[:])
+	fill(zobrist.leader[:])
+	fill(zobrist.defender[:])
+	fill(zobrist.mover[:])
+	fill(zobrist.seat[:])
+	fill(zobrist.passes[:])
+	fill(zobrist.deckSize[:])
+	zobrist.firstRound = rng.Uint64()
+}
+
+// cardIndex numbers the 52 cards from 0 to 51.
+func cardIndex(card Card) int {
+	return int(card.Suit)*13 + int(card.Rank-Two)
+}
+
+// Hash returns a Zobrist hash of the position: every hand, the table, the
+// turn, the number of cards in the deck and the trump card. The order of
+// the deck is left out, as are the order of the hands and of the table,
+// which do not change the game.
+func (b *Board) Hash() uint64 {
+	h := b.publicHash()
+	for p, hand := range b.Hands {
+		for _, card := range hand {
+			h ^= zobrist.hand[p][cardIndex(card)]
+		}
+	}
+	return h
+}
+
+// InfoHash returns a Zobrist hash of the position as seat sees it: seat's
+// own hand, how many cards the others hold, the table, the turn, the deck
+// size, the trump card and which cards have been discarded. Positions
+// only the hidden cards tell apart hash the same.
+func (b *Board) InfoHash(seat Player) uint64 {
+	h := b.publicHash() ^ zobrist.seat[seat]
+	for _, card := range b.Hands[seat] {
+		h ^= zobrist.hand[seat][cardIndex(card)]
+	}
+	for p, hand := range b.Hands {
+		h ^= zobrist.handSize[p][len(hand)]
+	}
+	// The cards still in play are the ones not discarded.
+	for _, hand := range b.Hands {
+		for _, card := range hand {
+			h ^= zobrist.inPlay[cardIndex(card)]
+		}
+	}
+	for _, card := range b.Deck {
+		h ^= zobrist.inPlay[cardIndex(card)]
+	}
+	for _, card := range b.TableCardList() {
+		h ^= zobrist.inPlay[cardIndex(card)]
+	}
+	return h
+}
+
+// publicHash hashes what everybody sees, save the discards.
+func (b *Board) publicHash() uint64 {
+	h := zobrist.trump[cardIndex(b.TrumpCard)] ^
+		zobrist.attacker[b.Attacker] ^
+		zobrist.leader[b.Leader] ^
+		zobrist.defender[b.Defender] ^
+		zobrist.passes[min(b.Passes, len(zobrist.passes)-1)] ^
+		zobrist.deckSize[len(b.Deck)]
+	if b.Round == 0 {
+		h ^= zobrist.firstRound
+	}
+	for _, tc := range b.Table {
+		if tc.Covered {
+			h ^= zobrist.covered[cardIndex(tc.Attack)] ^ zobrist.covered[cardIndex(tc.Defense)]
+		} else {
+			h ^= zobrist.uncovered[cardIndex(tc.Attack)]
+		}
+	}
+	return h
+}
@@ -0,0 +1,53 @@
+package durak
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestHashIgnoresOrder(t *testing.T) {
+	b := testBoard(t, "6♥ 7♥ 8♣", "9♥ 10♥ J♥")
+	b.Table = []TableCards{{Attack: cards(t, "6♦")[0]}, {Attack: cards(t, "6♣")[0], Defense: cards(t, "7♣")[0], Covered: true}}
+	shuffled := b.Copy()
+	slices.Reverse(shuffled.Hands[0])
+	slices.Reverse(shuffled.Hands[1])
+	slices.Reverse(shuffled.Table)
+	if b.Hash() != shuffled.Hash() {
+		t.Error("reordering the hands and the table changed Hash")
+	}
+	if b.InfoHash(0) != shuffled.InfoHash(0) {
+		t.Error("reordering the hands and the table changed InfoHash")
+	}
+
+	moved := b.Copy()
+	moved.Hands[0][0], moved.Hands[1][0] = moved.Hands[1][0], moved.Hands[0][0]
+	if b.Hash() == moved.Hash() {
+		t.Error("swapping cards between hands left Hash unchanged")
+	}
+}
+
+func TestInfoHashIgnoresHiddenCards(t *testing.T) {
+	e := &Engine{Rules: Rules{Deck: Deck36, Players: 3, AttackLimit: 6}}
+	rng := rand.New(rand.NewPCG(1, 2))
+	board := e.dealWith(rng)
+	for range 10 {
+		moves := e.GetLegalMoves(board)
+		e.PlayMove(board, moves[rng.IntN(len(moves))])
+	}
+
+	for seat := range Player(3) {
+		want := board.InfoHash(seat)
+		hashes := make(map[uint64]bool)
+		for range 20 {
+			det := (*Knowledge)(nil).Determinize(board, seat, rng)
+			if got := det.InfoHash(seat); got != want {
+				t.Fatalf("seat %d: InfoHash of a determinization = %x, want %x", seat, got, want)
+			}
+			hashes[det.Hash()] = true
+		}
+		if len(hashes) < 2 {
+			t.Errorf("seat %d: every determinization has the same Hash", seat)
+		}
+	}
+}